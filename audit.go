@@ -0,0 +1,170 @@
+package httpr
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+type AuditRecord struct {
+	Actor         string
+	Method        string
+	URL           string
+	Header        http.Header
+	StartAt       time.Time
+	EndAt         time.Time
+	Status        int
+	RequestBytes  int64
+	ResponseBytes int64
+	Err           string
+}
+
+type AuditSink interface {
+	Write(records []AuditRecord) error
+}
+
+type Auditor struct {
+	sink      AuditSink
+	batchSize int
+	interval  time.Duration
+	redact    map[string]bool
+	records   chan AuditRecord
+	done      chan struct{}
+	dropped   int64
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewAuditor(sink AuditSink, batchSize int, interval time.Duration) *Auditor {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	a := &Auditor{
+		sink:      sink,
+		batchSize: batchSize,
+		interval:  interval,
+		redact:    map[string]bool{},
+		records:   make(chan AuditRecord, batchSize*4),
+		done:      make(chan struct{}),
+	}
+	a.Redact("Authorization", "Proxy-Authorization", "Cookie")
+	go a.loop()
+	return a
+}
+
+func (a *Auditor) Redact(headers ...string) *Auditor {
+	for _, h := range headers {
+		a.redact[http.CanonicalHeaderKey(h)] = true
+	}
+	return a
+}
+
+func (a *Auditor) Record(record AuditRecord) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		atomic.AddInt64(&a.dropped, 1)
+		return
+	}
+	select {
+	case a.records <- record:
+	default:
+		atomic.AddInt64(&a.dropped, 1)
+	}
+}
+
+func (a *Auditor) Dropped() int64 {
+	return atomic.LoadInt64(&a.dropped)
+}
+
+func (a *Auditor) Close() {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return
+	}
+	a.closed = true
+	close(a.records)
+	a.mu.Unlock()
+	<-a.done
+}
+
+func (a *Auditor) loop() {
+	defer close(a.done)
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	batch := make([]AuditRecord, 0, a.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := a.sink.Write(batch); err != nil {
+			defaultLogger.Errorf("audit sink: %v\n", err)
+		}
+		batch = make([]AuditRecord, 0, a.batchSize)
+	}
+	for {
+		select {
+		case record, ok := <-a.records:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) >= a.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (a *Auditor) record(req *Request, rsp *Response, err error) {
+	record := AuditRecord{
+		Actor:         req.actor,
+		Method:        req.method,
+		StartAt:       req.startAt,
+		EndAt:         req.endAt,
+		RequestBytes:  -1,
+		ResponseBytes: -1,
+	}
+	if req.req != nil {
+		record.URL = req.redactor().url(req.req.URL)
+		record.RequestBytes = req.req.ContentLength
+		record.Header = make(http.Header, len(req.req.Header))
+		for key, values := range req.req.Header {
+			if a.redact[key] {
+				values = []string{redacted}
+			}
+			record.Header[key] = append([]string(nil), values...)
+		}
+	} else {
+		record.URL = req.redactor().rawURL(req.uri)
+	}
+	if rsp != nil {
+		record.Status = rsp.StatusCode()
+		record.ResponseBytes = rsp.rsp.ContentLength
+	}
+	if err != nil {
+		record.Err = err.Error()
+	}
+	a.Record(record)
+}
+
+func (s *Service) Audit(a *Auditor) *Service {
+	s.auditor = a
+	return s
+}
+
+func (req *Request) Actor(actor string) *Request {
+	req.actor = actor
+	return req
+}
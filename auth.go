@@ -0,0 +1,66 @@
+package httpr
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+)
+
+type credential struct {
+	header string
+	value  func(ctx context.Context) (string, error)
+}
+
+func (s *Service) BasicAuth(user, pass string) *Service {
+	value := "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+	return s.credential("Authorization", func(context.Context) (string, error) {
+		return value, nil
+	})
+}
+
+func (s *Service) BearerToken(token string) *Service {
+	return s.credential("Authorization", func(context.Context) (string, error) {
+		return "Bearer " + token, nil
+	})
+}
+
+func (s *Service) TokenFunc(fn func() string) *Service {
+	return s.credential("Authorization", func(context.Context) (string, error) {
+		return "Bearer " + fn(), nil
+	})
+}
+
+func (s *Service) APIKey(header, key string) *Service {
+	return s.credential(header, func(context.Context) (string, error) {
+		return key, nil
+	})
+}
+
+func (s *Service) credential(header string, value func(ctx context.Context) (string, error)) *Service {
+	header = http.CanonicalHeaderKey(header)
+	for i, c := range s.credentials {
+		if c.header == header {
+			s.credentials[i].value = value
+			return s
+		}
+	}
+	s.credentials = append(s.credentials, credential{header: header, value: value})
+	return s
+}
+
+func (req *Request) authorize(r *http.Request) error {
+	if req.service == nil {
+		return nil
+	}
+	for _, c := range req.service.credentials {
+		if _, ok := req.header[c.header]; ok {
+			continue
+		}
+		value, err := c.value(r.Context())
+		if err != nil {
+			return err
+		}
+		r.Header.Set(c.header, value)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package httpr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type OperationError struct {
+	URL     string
+	Status  string
+	Snippet string
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("httpr: async operation %s ended with status %s", e.URL, e.Status)
+}
+
+type operationStatus struct {
+	Status           string          `json:"status"`
+	Done             *bool           `json:"done"`
+	Error            json.RawMessage `json:"error"`
+	ResourceLocation string          `json:"resourceLocation"`
+}
+
+func (req *Request) derive(method, uri string) *Request {
+	if req.service != nil {
+		return req.service.Request(method, uri)
+	}
+	return NewRequest(method, uri).UseClient(req.httpClient)
+}
+
+func resolveLocation(rsp *Response, location string) string {
+	if u, err := rsp.rsp.Request.URL.Parse(location); err == nil {
+		return u.String()
+	}
+	return location
+}
+
+func (req *Request) AwaitAsync(ctx context.Context, pollInterval time.Duration) (rsp *Response, err error) {
+	if rsp, err = req.Do(ctx); err != nil || rsp.StatusCode() != http.StatusAccepted {
+		return
+	}
+	h := rsp.Header()
+	statusURL := h.Get("Operation-Location")
+	if statusURL == "" {
+		statusURL = h.Get("Azure-AsyncOperation")
+	}
+	location := h.Get("Location")
+	if statusURL == "" {
+		statusURL, location = location, ""
+	}
+	if statusURL == "" {
+		return
+	}
+	statusURL = resolveLocation(rsp, statusURL)
+	if location != "" {
+		location = resolveLocation(rsp, location)
+	}
+	for {
+		wait := pollInterval
+		if d, ok := retryAfter(rsp.Header().Get("Retry-After")); ok {
+			wait = d
+		}
+		discard(rsp)
+		if err = sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		if rsp, err = req.derive(http.MethodGet, statusURL).Do(ctx); err != nil {
+			return
+		}
+		code := rsp.StatusCode()
+		if code == http.StatusAccepted {
+			continue
+		}
+		if code >= 400 {
+			return rsp, rsp.statusError()
+		}
+		var status operationStatus
+		if bs, berr := rsp.Bytes(); berr == nil {
+			json.Unmarshal(bs, &status)
+		}
+		switch strings.ToLower(status.Status) {
+		case "", "succeeded", "success", "completed", "done":
+		case "failed", "canceled", "cancelled", "error":
+			return rsp, &OperationError{URL: statusURL, Status: status.Status, Snippet: rsp.snippet()}
+		default:
+			continue
+		}
+		if status.Done != nil && !*status.Done {
+			continue
+		}
+		if status.Done != nil && len(status.Error) > 0 && string(status.Error) != "null" {
+			return rsp, &OperationError{URL: statusURL, Status: "error", Snippet: rsp.snippet()}
+		}
+		if status.ResourceLocation != "" {
+			location = resolveLocation(rsp, status.ResourceLocation)
+		}
+		if location == "" || location == statusURL {
+			return
+		}
+		discard(rsp)
+		return req.derive(http.MethodGet, location).Do(ctx)
+	}
+}
@@ -0,0 +1,222 @@
+package httpr
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Strategy int
+
+const (
+	RoundRobin Strategy = iota
+	Random
+	Weighted
+)
+
+type hostEntry struct {
+	addr      string
+	url       *url.URL
+	weight    int
+	failures  int
+	downUntil time.Time
+}
+
+type balancer struct {
+	mu       sync.Mutex
+	hosts    []*hostEntry
+	strategy Strategy
+	next     int
+	cooldown time.Duration
+	maxFails int
+}
+
+func (s *Service) Hosts(hosts ...string) *Service {
+	for _, host := range hosts {
+		s.HostWeight(host, 1)
+	}
+	return s
+}
+
+func (s *Service) HostWeight(host string, weight int) *Service {
+	host = strings.TrimSuffix(host, "/")
+	u, err := url.Parse(host)
+	if err != nil || u.Host == "" {
+		s.err = fmt.Errorf("%w: %s", ErrInvalidHost, host)
+		return s
+	}
+	b := s.lb()
+	b.mu.Lock()
+	b.hosts = append(b.hosts, &hostEntry{addr: host, url: u, weight: weight})
+	b.mu.Unlock()
+	s.hosts = append(s.hosts, host)
+	if s.host == "" {
+		s.host = host
+	}
+	return s
+}
+
+func (s *Service) Balance(strategy Strategy) *Service {
+	s.lb().strategy = strategy
+	return s
+}
+
+func (s *Service) HealthCheck(maxFails int, cooldown time.Duration) *Service {
+	b := s.lb()
+	b.maxFails = maxFails
+	b.cooldown = cooldown
+	return s
+}
+
+func (s *Service) lb() *balancer {
+	if s.balancer == nil {
+		s.balancer = &balancer{
+			cooldown: 30 * time.Second,
+			maxFails: 1,
+		}
+	}
+	return s.balancer
+}
+
+func (b *balancer) size() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.hosts)
+}
+
+func (b *balancer) pick(tried map[*hostEntry]bool) *hostEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	var candidates []*hostEntry
+	for _, h := range b.hosts {
+		if !tried[h] && !now.Before(h.downUntil) {
+			candidates = append(candidates, h)
+		}
+	}
+	if len(candidates) == 0 {
+		for _, h := range b.hosts {
+			if !tried[h] {
+				candidates = append(candidates, h)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	switch b.strategy {
+	case Random:
+		return candidates[rand.Intn(len(candidates))]
+	case Weighted:
+		total := 0
+		for _, h := range candidates {
+			total += h.weight
+		}
+		if total <= 0 {
+			return candidates[rand.Intn(len(candidates))]
+		}
+		n := rand.Intn(total)
+		for _, h := range candidates {
+			if n < h.weight {
+				return h
+			}
+			n -= h.weight
+		}
+	}
+	h := candidates[b.next%len(candidates)]
+	b.next++
+	return h
+}
+
+func (b *balancer) health(now time.Time) (hosts []HostHealth) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, h := range b.hosts {
+		hh := HostHealth{Host: h.addr, Failures: h.failures, Ejected: now.Before(h.downUntil)}
+		if hh.Ejected {
+			until := h.downUntil
+			hh.DownUntil = &until
+		}
+		hosts = append(hosts, hh)
+	}
+	return
+}
+
+func (b *balancer) report(h *hostEntry, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		h.failures = 0
+		h.downUntil = time.Time{}
+		return
+	}
+	h.failures++
+	if h.failures >= b.maxFails {
+		h.downUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+func (req *Request) balancer() *balancer {
+	if req.service == nil || req.service.balancer == nil {
+		return nil
+	}
+	if req.host != req.service.host || isAbsoluteURL(req.uri) {
+		return nil
+	}
+	return req.service.balancer
+}
+
+func (req *Request) send(r *http.Request, target url.URL) (rsp *Response, err error) {
+	b := req.balancer()
+	if b == nil {
+		return req._do(r)
+	}
+	path, rawPath := target.Path, target.RawPath
+	if u, perr := url.Parse(req.host); perr == nil && u.Path != "" {
+		path = strings.TrimPrefix(path, u.Path)
+		rawPath = strings.TrimPrefix(rawPath, u.EscapedPath())
+	}
+	tried := map[*hostEntry]bool{}
+	for {
+		h := b.pick(tried)
+		if h == nil {
+			return
+		}
+		if len(tried) > 0 && r.GetBody != nil {
+			if r.Body, err = r.GetBody(); err != nil {
+				return
+			}
+		}
+		tried[h] = true
+		u := target
+		u.Scheme = h.url.Scheme
+		u.Host = h.url.Host
+		u.Path = h.url.Path + path
+		if rawPath != "" {
+			u.RawPath = h.url.EscapedPath() + rawPath
+		}
+		r.URL = &u
+		if req.hostHeader == "" {
+			r.Host = ""
+		}
+		rsp, err = req._do(r)
+		failed := err != nil || rsp.StatusCode() >= 500
+		b.report(h, !failed)
+		if !failed || len(tried) >= b.size() || !failover(r, err) {
+			return
+		}
+		discard(rsp)
+	}
+}
+
+func failover(r *http.Request, err error) bool {
+	if idempotent(r) {
+		return true
+	}
+	class := ClassOf(err)
+	return class == ClassDNS || class == ClassRefused
+}
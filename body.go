@@ -0,0 +1,91 @@
+package httpr
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"sync"
+)
+
+func (req *Request) Body(body io.Reader) *Request {
+	req.body, req.bodyBytes = body, nil
+	if bs, ok := snapshotBody(body); ok {
+		req.setBody(bs)
+	}
+	return req
+}
+
+func (req *Request) setBody(bs []byte) {
+	if bs == nil {
+		bs = []byte{}
+	}
+	req.body, req.bodyBytes = bytes.NewReader(bs), bs
+}
+
+func (req *Request) Text(text string) *Request {
+	req.setBody([]byte(text))
+	req.contentType = "text/plain; charset=utf-8"
+	return req
+}
+
+func (req *Request) Json(obj interface{}) *Request {
+	bs, err := json.Marshal(obj)
+	if err != nil {
+		req.fail(err)
+		return req
+	}
+	req.setBody(bs)
+	req.contentType = "application/json"
+	return req
+}
+
+func (req *Request) Form(values url.Values) *Request {
+	req.setBody([]byte(values.Encode()))
+	req.contentType = "application/x-www-form-urlencoded"
+	return req
+}
+
+func (req *Request) FormStruct(v interface{}) *Request {
+	values, err := encodeValues(v, "form")
+	if err != nil {
+		return req.fail(err)
+	}
+	return req.Form(values)
+}
+
+func (req *Request) BodyFromChan(chunks <-chan []byte) *Request {
+	req.body, req.bodyBytes = &chanReader{chunks: chunks, done: make(chan struct{})}, nil
+	return req
+}
+
+type chanReader struct {
+	chunks <-chan []byte
+	buf    []byte
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (r *chanReader) Read(p []byte) (n int, err error) {
+	for len(r.buf) == 0 {
+		select {
+		case chunk, ok := <-r.chunks:
+			if !ok {
+				return 0, io.EOF
+			}
+			r.buf = chunk
+		case <-r.done:
+			return 0, io.ErrClosedPipe
+		}
+	}
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return
+}
+
+func (r *chanReader) Close() error {
+	r.once.Do(func() {
+		close(r.done)
+	})
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build brotli
+
+package httpr
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/andybalholm/brotli"
+)
+
+func init() {
+	RegisterContentEncoding("br", func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(brotli.NewReader(r)), nil
+	})
+}
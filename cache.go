@@ -0,0 +1,261 @@
+package httpr
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const CacheStatusHeader = "X-Httpr-Cache"
+
+type CachedResponse struct {
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+	Vary         map[string]string
+	RequestTime  time.Time
+	ResponseTime time.Time
+}
+
+type Cache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, entry *CachedResponse)
+	Delete(key string)
+}
+
+type lruItem struct {
+	key   string
+	entry *CachedResponse
+}
+
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      map[string]*list.Element{},
+	}
+}
+
+func (c *LRUCache) Get(key string) (entry *CachedResponse, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.ll.MoveToFront(e)
+	entry = e.Value.(*lruItem).entry
+	return
+}
+
+func (c *LRUCache) Set(key string, entry *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*lruItem).entry = entry
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruItem{key: key, entry: entry})
+	for c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+}
+
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (s *Service) Cache(c Cache) *Service {
+	return s.Use(CacheMiddleware(c))
+}
+
+func cacheKey(r *http.Request) string {
+	return r.Method + " " + r.URL.String()
+}
+
+func varyValues(h http.Header, r *http.Request) map[string]string {
+	var values map[string]string
+	for _, line := range h.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if values == nil {
+				values = map[string]string{}
+			}
+			values[name] = r.Header.Get(name)
+		}
+	}
+	return values
+}
+
+func (e *CachedResponse) matches(r *http.Request) bool {
+	for name, value := range e.Vary {
+		if name == "*" || r.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *CachedResponse) response(r *http.Request, status string) *http.Response {
+	header := e.Header.Clone()
+	header.Set(CacheStatusHeader, status)
+	age := time.Since(e.ResponseTime)
+	if age > 0 {
+		header.Set("Age", fmt.Sprint(int64(age/time.Second)))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       r,
+	}
+}
+
+func storable(r *http.Request, resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	directives := cacheControl(resp.Header)
+	if _, ok := directives["no-store"]; ok {
+		return false
+	}
+	if _, ok := directives["private"]; ok {
+		return false
+	}
+	if _, ok := cacheControl(r.Header)["no-store"]; ok {
+		return false
+	}
+	if r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "" {
+		_, public := directives["public"]
+		_, shared := directives["s-maxage"]
+		_, revalidate := directives["must-revalidate"]
+		if !public && !shared && !revalidate {
+			return false
+		}
+	}
+	if resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "" {
+		return true
+	}
+	_, ok := freshness(resp.Header, time.Time{}, time.Now(), time.Now())
+	return ok
+}
+
+func CacheMiddleware(c Cache) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (resp *http.Response, err error) {
+			if r.Method != http.MethodGet || r.Header.Get("Range") != "" {
+				return next(r)
+			}
+			key := cacheKey(r)
+			entry, ok := c.Get(key)
+			if ok && !entry.matches(r) {
+				ok = false
+			}
+			_, noCache := cacheControl(r.Header)["no-cache"]
+			if ok && !noCache {
+				if ttl, known := freshness(entry.Header, entry.RequestTime, entry.ResponseTime, time.Now()); known && ttl > 0 {
+					return entry.response(r, "HIT"), nil
+				}
+			}
+			out := r
+			if ok && r.Header.Get("If-None-Match") == "" && r.Header.Get("If-Modified-Since") == "" {
+				etag, lastModified := entry.Header.Get("ETag"), entry.Header.Get("Last-Modified")
+				if etag != "" || lastModified != "" {
+					out = r.Clone(r.Context())
+					if etag != "" {
+						out.Header.Set("If-None-Match", etag)
+					}
+					if lastModified != "" {
+						out.Header.Set("If-Modified-Since", lastModified)
+					}
+				}
+			}
+			requestTime := time.Now()
+			if resp, err = next(out); err != nil {
+				return
+			}
+			responseTime := time.Now()
+			if ok && out != r && resp.StatusCode == http.StatusNotModified {
+				resp.Body.Close()
+				updated := *entry
+				updated.Header = entry.Header.Clone()
+				for key, values := range resp.Header {
+					updated.Header[key] = values
+				}
+				updated.RequestTime, updated.ResponseTime = requestTime, responseTime
+				c.Set(key, &updated)
+				return updated.response(r, "REVALIDATED"), nil
+			}
+			if !storable(r, resp) {
+				if ok && resp.StatusCode != http.StatusNotModified {
+					c.Delete(key)
+				}
+				return
+			}
+			limit := responseLimitOf(r)
+			if limit > 0 && resp.ContentLength > limit {
+				return
+			}
+			var src io.Reader = resp.Body
+			if limit > 0 {
+				src = io.LimitReader(resp.Body, limit+1)
+			}
+			body, err := ioutil.ReadAll(src)
+			if err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			if limit > 0 && int64(len(body)) > limit {
+				resp.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+				return
+			}
+			resp.Body.Close()
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			c.Set(key, &CachedResponse{
+				StatusCode:   resp.StatusCode,
+				Header:       resp.Header.Clone(),
+				Body:         body,
+				Vary:         varyValues(resp.Header, r),
+				RequestTime:  requestTime,
+				ResponseTime: responseTime,
+			})
+			resp.Header.Set(CacheStatusHeader, "MISS")
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package httpr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCacheRevalidation(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	s := NewService(nil).Cache(NewLRUCache(8))
+	for i, want := range []string{"MISS", "REVALIDATED"} {
+		rsp, err := s.Get(server.URL).Response()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := rsp.String()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != "hello" {
+			t.Errorf("request %d: body = %q, want %q", i, body, "hello")
+		}
+		if got := rsp.Header().Get(CacheStatusHeader); got != want {
+			t.Errorf("request %d: %s = %q, want %q", i, CacheStatusHeader, got, want)
+		}
+	}
+	if hits != 2 {
+		t.Errorf("server hits = %d, want 2", hits)
+	}
+}
+
+func TestCacheSkipsAuthenticatedResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	cache := NewLRUCache(8)
+	s := NewService(nil).Cache(cache)
+	for _, user := range []string{"alice", "bob"} {
+		rsp, err := s.Get(server.URL).Header("Authorization", user).Response()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body, _ := rsp.String(); body != user {
+			t.Errorf("body = %q, want %q", body, user)
+		}
+	}
+	if n := cache.Len(); n != 0 {
+		t.Errorf("cache entries = %d, want 0", n)
+	}
+}
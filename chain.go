@@ -0,0 +1,70 @@
+package httpr
+
+import (
+	"context"
+	"fmt"
+)
+
+type Step func(prev *Response) (*Request, error)
+
+type PipelineError struct {
+	Step    int
+	Request string
+	Err     error
+}
+
+func (e *PipelineError) Error() string {
+	if e.Request == "" {
+		return fmt.Sprintf("httpr: pipeline step %d: %v", e.Step, e.Err)
+	}
+	return fmt.Sprintf("httpr: pipeline step %d (%s): %v", e.Step, e.Request, e.Err)
+}
+
+func (e *PipelineError) Unwrap() error {
+	return e.Err
+}
+
+type Pipeline struct {
+	first     *Request
+	steps     []Step
+	responses []*Response
+}
+
+func NewPipeline(first *Request) *Pipeline {
+	return &Pipeline{first: first}
+}
+
+func (p *Pipeline) Then(step Step) *Pipeline {
+	p.steps = append(p.steps, step)
+	return p
+}
+
+func (p *Pipeline) Responses() []*Response {
+	return p.responses
+}
+
+func (p *Pipeline) Run(ctx context.Context) (rsp *Response, err error) {
+	p.responses = nil
+	req := p.first
+	for i := 0; ; i++ {
+		if rsp, err = req.Do(ctx); err != nil {
+			err = &PipelineError{Step: i, Request: req.String(), Err: err}
+			return
+		}
+		p.responses = append(p.responses, rsp)
+		if i == len(p.steps) {
+			return
+		}
+		if err = ctx.Err(); err != nil {
+			err = &PipelineError{Step: i + 1, Err: err}
+			return
+		}
+		if req, err = p.steps[i](rsp); err != nil {
+			err = &PipelineError{Step: i + 1, Err: err}
+			return
+		}
+		if req == nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package httpr
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+type CharsetDecoder func(body []byte) (string, error)
+
+var (
+	charsetMu       sync.RWMutex
+	charsetDecoders = map[string]CharsetDecoder{
+		"utf-8":        decodeUTF8,
+		"us-ascii":     decodeUTF8,
+		"utf-16le":     decodeUTF16(false),
+		"utf-16be":     decodeUTF16(true),
+		"iso-8859-1":   decodeLatin1,
+		"latin1":       decodeLatin1,
+		"windows-1252": decodeWindows1252,
+		"cp1252":       decodeWindows1252,
+	}
+	metaCharsetPattern = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([a-zA-Z0-9_:.\-]+)`)
+)
+
+func RegisterCharset(name string, decoder CharsetDecoder) {
+	charsetMu.Lock()
+	defer charsetMu.Unlock()
+	charsetDecoders[strings.ToLower(name)] = decoder
+}
+
+func (s *Service) SniffCharset() *Service {
+	s.sniffCharset = true
+	return s
+}
+
+func (rsp *Response) Charset() string {
+	if _, params, err := mime.ParseMediaType(rsp.rsp.Header.Get("Content-Type")); err == nil && params["charset"] != "" {
+		return strings.ToLower(params["charset"])
+	}
+	if rsp.req.service == nil || !rsp.req.service.sniffCharset {
+		return "utf-8"
+	}
+	body, err := rsp.Bytes()
+	if err != nil {
+		return "utf-8"
+	}
+	return sniffCharset(body)
+}
+
+func sniffCharset(body []byte) string {
+	switch {
+	case bytes.HasPrefix(body, []byte{0xef, 0xbb, 0xbf}):
+		return "utf-8"
+	case bytes.HasPrefix(body, []byte{0xff, 0xfe}):
+		return "utf-16le"
+	case bytes.HasPrefix(body, []byte{0xfe, 0xff}):
+		return "utf-16be"
+	}
+	head := body
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	if m := metaCharsetPattern.FindSubmatch(head); m != nil {
+		return strings.ToLower(string(m[1]))
+	}
+	return "utf-8"
+}
+
+func (rsp *Response) String() (s string, err error) {
+	body, err := rsp.Bytes()
+	if err != nil {
+		return
+	}
+	charset := rsp.Charset()
+	charsetMu.RLock()
+	decoder, ok := charsetDecoders[charset]
+	charsetMu.RUnlock()
+	if !ok {
+		err = errors.New("httpr: unsupported charset " + charset)
+		return
+	}
+	return decoder(body)
+}
+
+func decodeUTF8(body []byte) (string, error) {
+	return string(bytes.TrimPrefix(body, []byte{0xef, 0xbb, 0xbf})), nil
+}
+
+func decodeLatin1(body []byte) (string, error) {
+	var b strings.Builder
+	b.Grow(len(body))
+	for _, c := range body {
+		b.WriteRune(rune(c))
+	}
+	return b.String(), nil
+}
+
+var windows1252 = [32]rune{
+	0x20ac, 0x0081, 0x201a, 0x0192, 0x201e, 0x2026, 0x2020, 0x2021,
+	0x02c6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008d, 0x017d, 0x008f,
+	0x0090, 0x2018, 0x2019, 0x201c, 0x201d, 0x2022, 0x2013, 0x2014,
+	0x02dc, 0x2122, 0x0161, 0x203a, 0x0153, 0x009d, 0x017e, 0x0178,
+}
+
+func decodeWindows1252(body []byte) (string, error) {
+	var b strings.Builder
+	b.Grow(len(body))
+	for _, c := range body {
+		if c >= 0x80 && c < 0xa0 {
+			b.WriteRune(windows1252[c-0x80])
+			continue
+		}
+		b.WriteRune(rune(c))
+	}
+	return b.String(), nil
+}
+
+func decodeUTF16(bigEndian bool) CharsetDecoder {
+	return func(body []byte) (string, error) {
+		if bytes.HasPrefix(body, []byte{0xff, 0xfe}) || bytes.HasPrefix(body, []byte{0xfe, 0xff}) {
+			body = body[2:]
+		}
+		if len(body)%2 != 0 {
+			return "", errors.New("httpr: odd length utf-16 body")
+		}
+		units := make([]uint16, len(body)/2)
+		for i := range units {
+			if bigEndian {
+				units[i] = uint16(body[2*i])<<8 | uint16(body[2*i+1])
+			} else {
+				units[i] = uint16(body[2*i+1])<<8 | uint16(body[2*i])
+			}
+		}
+		return string(utf16.Decode(units)), nil
+	}
+}
@@ -0,0 +1,88 @@
+package httpr
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func (req *Request) Clone() *Request {
+	c := *req
+	c.used = 0
+	c.req = nil
+	c.reqCtx = nil
+	c.cancel = nil
+	c.redirects = nil
+	c.decodeContent = false
+	c.startAt, c.endAt = time.Time{}, time.Time{}
+	c.header = req.header.Clone()
+	c.params = cloneValues(req.params)
+	c.pathParams = cloneStrings(req.pathParams)
+	c.tags = cloneStrings(req.tags)
+	c.dropped = cloneSet(req.dropped)
+	c.inherited = cloneSet(req.inherited)
+	c.retries = append(c.retries[:0:0], req.retries...)
+	c.retryOn = append(c.retryOn[:0:0], req.retryOn...)
+	c.cookies = append(c.cookies[:0:0], req.cookies...)
+	c.expectTypes = append(c.expectTypes[:0:0], req.expectTypes...)
+	c.expectStatus = append(c.expectStatus[:0:0], req.expectStatus...)
+	c.before = append(c.before[:0:0], req.before...)
+	c.beforeRequest = append(c.beforeRequest[:0:0], req.beforeRequest...)
+	c.afterHooks = append(c.afterHooks[:0:0], req.afterHooks...)
+	c.after = append(c.after[:0:0], req.after...)
+	if req.compressed != "" {
+		c.compress, c.compressed = true, ""
+	}
+	if req.bodyBytes != nil {
+		c.body = bytes.NewReader(req.bodyBytes)
+	} else if req.body != nil {
+		c.body = nil
+		c.fail(ErrBodyNotCloned)
+	}
+	return &c
+}
+
+func snapshotBody(body io.Reader) (bs []byte, ok bool) {
+	switch body.(type) {
+	case *bytes.Reader, *bytes.Buffer, *strings.Reader:
+		bs, _ = ioutil.ReadAll(body)
+		ok = true
+	}
+	return
+}
+
+func cloneValues(v url.Values) url.Values {
+	if v == nil {
+		return nil
+	}
+	c := make(url.Values, len(v))
+	for key, values := range v {
+		c[key] = append([]string(nil), values...)
+	}
+	return c
+}
+
+func cloneStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func cloneSet(m map[string]bool) map[string]bool {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]bool, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
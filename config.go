@@ -0,0 +1,281 @@
+package httpr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+type RetryConfig struct {
+	MaxAttempts        int      `json:"max_attempts" yaml:"max_attempts" toml:"max_attempts"`
+	BaseDelay          Duration `json:"base_delay" yaml:"base_delay" toml:"base_delay"`
+	MaxDelay           Duration `json:"max_delay" yaml:"max_delay" toml:"max_delay"`
+	Jitter             float64  `json:"jitter" yaml:"jitter" toml:"jitter"`
+	RetryOnStatus      []int    `json:"retry_on_status" yaml:"retry_on_status" toml:"retry_on_status"`
+	RespectRetryAfter  bool     `json:"respect_retry_after" yaml:"respect_retry_after" toml:"respect_retry_after"`
+	AllowNonIdempotent bool     `json:"allow_non_idempotent" yaml:"allow_non_idempotent" toml:"allow_non_idempotent"`
+}
+
+type AuthConfig struct {
+	Type     string `json:"type" yaml:"type" toml:"type"`
+	Username string `json:"username" yaml:"username" toml:"username"`
+	Password string `json:"password" yaml:"password" toml:"password"`
+	Token    string `json:"token" yaml:"token" toml:"token"`
+	Header   string `json:"header" yaml:"header" toml:"header"`
+	Key      string `json:"key" yaml:"key" toml:"key"`
+}
+
+type ServiceConfig struct {
+	Host                  string            `json:"host" yaml:"host" toml:"host"`
+	Hosts                 []string          `json:"hosts" yaml:"hosts" toml:"hosts"`
+	Headers               map[string]string `json:"headers" yaml:"headers" toml:"headers"`
+	Paths                 map[string]string `json:"paths" yaml:"paths" toml:"paths"`
+	Timeout               Duration          `json:"timeout" yaml:"timeout" toml:"timeout"`
+	DialTimeout           Duration          `json:"dial_timeout" yaml:"dial_timeout" toml:"dial_timeout"`
+	TLSHandshakeTimeout   Duration          `json:"tls_handshake_timeout" yaml:"tls_handshake_timeout" toml:"tls_handshake_timeout"`
+	ResponseHeaderTimeout Duration          `json:"response_header_timeout" yaml:"response_header_timeout" toml:"response_header_timeout"`
+	Debug                 bool              `json:"debug" yaml:"debug" toml:"debug"`
+	DebugCurl             bool              `json:"debug_curl" yaml:"debug_curl" toml:"debug_curl"`
+	Retry                 *RetryConfig      `json:"retry" yaml:"retry" toml:"retry"`
+	Auth                  *AuthConfig       `json:"auth" yaml:"auth" toml:"auth"`
+}
+
+type RepoConfig struct {
+	Services map[string]ServiceConfig `json:"services" yaml:"services" toml:"services"`
+}
+
+type ConfigError struct {
+	Service string
+	Field   string
+	Err     error
+}
+
+func (e *ConfigError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("httpr: config service %q: %v", e.Service, e.Err)
+	}
+	return fmt.Sprintf("httpr: config service %q field %q: %v", e.Service, e.Field, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+func (r *Repo) LoadFile(path string) (err error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	err = r.LoadReader(bytes.NewReader(bs), strings.TrimPrefix(filepath.Ext(path), "."))
+	return
+}
+
+func (r *Repo) LoadReader(reader io.Reader, format string) (err error) {
+	bs, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return
+	}
+	var conf RepoConfig
+	switch strings.ToLower(format) {
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(bs))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&conf)
+	case "yaml", "yml":
+		dec := yaml.NewDecoder(bytes.NewReader(bs))
+		dec.KnownFields(true)
+		err = dec.Decode(&conf)
+	case "toml":
+		var md toml.MetaData
+		md, err = toml.Decode(string(bs), &conf)
+		if err == nil {
+			if undecoded := md.Undecoded(); len(undecoded) > 0 {
+				err = fmt.Errorf("unknown field %s", undecoded[0])
+			}
+		}
+	default:
+		err = fmt.Errorf("httpr: unsupported config format %q", format)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("httpr: parse %s config: %w", format, err)
+		return
+	}
+	return r.Load(conf)
+}
+
+func (r *Repo) Load(conf RepoConfig) (err error) {
+	names := make([]string, 0, len(conf.Services))
+	for name := range conf.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	services := make([]*Service, len(names))
+	for i, name := range names {
+		if services[i], err = conf.Services[name].build(name); err != nil {
+			return
+		}
+	}
+	for i, name := range names {
+		r.Register(name, services[i])
+	}
+	return
+}
+
+func (c ServiceConfig) validate(name string) error {
+	invalid := func(field string, format string, args ...interface{}) error {
+		return &ConfigError{Service: name, Field: field, Err: fmt.Errorf(format, args...)}
+	}
+	if c.Host == "" && len(c.Hosts) == 0 {
+		return invalid("host", "host or hosts is required")
+	}
+	for i, host := range append([]string{c.Host}, c.Hosts...) {
+		if host == "" && i == 0 {
+			continue
+		}
+		u, err := url.Parse(host)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			field := "host"
+			if i > 0 {
+				field = fmt.Sprintf("hosts[%d]", i-1)
+			}
+			return invalid(field, "%q is not an absolute URL", host)
+		}
+	}
+	for _, d := range []struct {
+		field string
+		value Duration
+	}{
+		{"timeout", c.Timeout},
+		{"dial_timeout", c.DialTimeout},
+		{"tls_handshake_timeout", c.TLSHandshakeTimeout},
+		{"response_header_timeout", c.ResponseHeaderTimeout},
+	} {
+		if d.value < 0 {
+			return invalid(d.field, "must not be negative")
+		}
+	}
+	for key, path := range c.Paths {
+		if path == "" {
+			return invalid("paths."+key, "path is empty")
+		}
+	}
+	if rc := c.Retry; rc != nil {
+		if rc.MaxAttempts < 0 {
+			return invalid("retry.max_attempts", "must not be negative")
+		}
+		if rc.Jitter < 0 || rc.Jitter > 1 {
+			return invalid("retry.jitter", "must be between 0 and 1")
+		}
+		if rc.MaxDelay > 0 && rc.MaxDelay < rc.BaseDelay {
+			return invalid("retry.max_delay", "is less than base_delay")
+		}
+	}
+	if a := c.Auth; a != nil {
+		switch a.Type {
+		case "basic":
+			if a.Username == "" {
+				return invalid("auth.username", "required for basic auth")
+			}
+		case "bearer":
+			if a.Token == "" {
+				return invalid("auth.token", "required for bearer auth")
+			}
+		case "api_key":
+			if a.Header == "" {
+				return invalid("auth.header", "required for api_key auth")
+			}
+			if a.Key == "" {
+				return invalid("auth.key", "required for api_key auth")
+			}
+		default:
+			return invalid("auth.type", "unknown auth type %q", a.Type)
+		}
+	}
+	return nil
+}
+
+func (c ServiceConfig) build(name string) (s *Service, err error) {
+	if err = c.validate(name); err != nil {
+		return
+	}
+	conf := Conf{
+		Timeout:   20 * time.Second,
+		Debug:     c.Debug,
+		DebugCurl: c.DebugCurl,
+	}
+	if c.Timeout > 0 {
+		conf.Timeout = time.Duration(c.Timeout)
+	}
+	s = NewService(&conf).Name(name)
+	if c.Host != "" {
+		s.Host(c.Host)
+	}
+	if len(c.Hosts) > 0 {
+		s.Hosts(c.Hosts...)
+	}
+	for key, value := range c.Headers {
+		s.Header(key, os.ExpandEnv(value))
+	}
+	for key, path := range c.Paths {
+		s.Paths(key, path)
+	}
+	if c.DialTimeout > 0 {
+		s.DialTimeout(time.Duration(c.DialTimeout))
+	}
+	if c.TLSHandshakeTimeout > 0 {
+		s.TLSHandshakeTimeout(time.Duration(c.TLSHandshakeTimeout))
+	}
+	if c.ResponseHeaderTimeout > 0 {
+		s.ResponseHeaderTimeout(time.Duration(c.ResponseHeaderTimeout))
+	}
+	if rc := c.Retry; rc != nil {
+		s.Retry(&RetryPolicy{
+			MaxAttempts:        rc.MaxAttempts,
+			BaseDelay:          time.Duration(rc.BaseDelay),
+			MaxDelay:           time.Duration(rc.MaxDelay),
+			Jitter:             rc.Jitter,
+			RetryOnStatus:      rc.RetryOnStatus,
+			RespectRetryAfter:  rc.RespectRetryAfter,
+			AllowNonIdempotent: rc.AllowNonIdempotent,
+		})
+	}
+	if a := c.Auth; a != nil {
+		switch a.Type {
+		case "basic":
+			s.BasicAuth(os.ExpandEnv(a.Username), os.ExpandEnv(a.Password))
+		case "bearer":
+			s.BearerToken(os.ExpandEnv(a.Token))
+		case "api_key":
+			s.APIKey(a.Header, os.ExpandEnv(a.Key))
+		}
+	}
+	err = s.err
+	return
+}
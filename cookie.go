@@ -0,0 +1,26 @@
+package httpr
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+)
+
+func (s *Service) WithCookieJar(jar http.CookieJar) *Service {
+	s.client.Jar = jar
+	s.resetSNIClients()
+	return s
+}
+
+func (s *Service) DefaultCookieJar() *Service {
+	jar, _ := cookiejar.New(nil)
+	return s.WithCookieJar(jar)
+}
+
+func (req *Request) Cookie(name, value string) *Request {
+	req.cookies = append(req.cookies, &http.Cookie{Name: name, Value: value})
+	return req
+}
+
+func (rsp *Response) Cookies() []*http.Cookie {
+	return rsp.rsp.Cookies()
+}
@@ -0,0 +1,94 @@
+package httpr
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Cost struct {
+	Tags          map[string]string
+	Method        string
+	URL           string
+	Status        int
+	RequestBytes  int64
+	ResponseBytes int64
+	Latency       time.Duration
+	Duration      time.Duration
+}
+
+type CostFunc func(cost Cost)
+
+func (s *Service) CostHook(hooks ...CostFunc) *Service {
+	s.costHooks = append(s.costHooks, hooks...)
+	return s
+}
+
+func (req *Request) Tag(key, value string) *Request {
+	if req.tags == nil {
+		req.tags = map[string]string{}
+	}
+	req.tags[key] = value
+	return req
+}
+
+func (req *Request) Tags() map[string]string {
+	tags := make(map[string]string, len(req.tags))
+	for k, v := range req.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+func (req *Request) trackCost(r *http.Request, resp *http.Response, start time.Time) {
+	if req.service == nil || len(req.service.costHooks) == 0 {
+		return
+	}
+	cost := Cost{
+		Tags:         req.Tags(),
+		Method:       r.Method,
+		URL:          req.redactor().url(r.URL),
+		Status:       resp.StatusCode,
+		RequestBytes: r.ContentLength,
+		Latency:      time.Since(start),
+	}
+	hooks := req.service.costHooks
+	resp.Body = &countingBody{
+		ReadCloser: resp.Body,
+		done: func(n int64) {
+			cost.ResponseBytes = n
+			cost.Duration = time.Since(start)
+			for _, hook := range hooks {
+				hook(cost)
+			}
+		},
+	}
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n    int64
+	once sync.Once
+	done func(n int64)
+}
+
+func (b *countingBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if err == io.EOF {
+		b.finish()
+	}
+	return
+}
+
+func (b *countingBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *countingBody) finish() {
+	b.once.Do(func() {
+		b.done(b.n)
+	})
+}
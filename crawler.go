@@ -0,0 +1,158 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Page struct {
+	URL      *url.URL
+	Depth    int
+	Response *Response
+	Err      error
+}
+
+type CrawlFunc func(page *Page) (links []string)
+
+type Crawler struct {
+	MaxDepth    int
+	Concurrency int
+	Filter      func(u *url.URL) bool
+
+	service *Service
+	limiter *HostLimiter
+	seeds   []string
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func NewCrawler(s *Service, seeds ...string) *Crawler {
+	return &Crawler{
+		MaxDepth:    2,
+		Concurrency: 8,
+		service:     s,
+		limiter:     NewHostLimiter(2, time.Second),
+		seeds:       seeds,
+		seen:        map[string]bool{},
+	}
+}
+
+func (c *Crawler) HostLimits(concurrency int, interval time.Duration) *Crawler {
+	c.limiter = NewHostLimiter(concurrency, interval)
+	return c
+}
+
+func (c *Crawler) Seed(urls ...string) *Crawler {
+	c.seeds = append(c.seeds, urls...)
+	return c
+}
+
+func (c *Crawler) Sitemap(ctx context.Context, sitemapURL string) (err error) {
+	rsp, err := c.service.Get(sitemapURL).Context(ctx).Limiter(c.limiter).Response()
+	if err != nil {
+		return
+	}
+	urls, sitemaps, err := ParseSitemap(rsp)
+	if err != nil {
+		return
+	}
+	c.Seed(urls...)
+	for _, sm := range sitemaps {
+		if err = c.Sitemap(ctx, sm); err != nil {
+			return
+		}
+	}
+	return
+}
+
+type sitemap struct {
+	URLs     []string `xml:"url>loc"`
+	Sitemaps []string `xml:"sitemap>loc"`
+}
+
+func ParseSitemap(rsp *Response) (urls, sitemaps []string, err error) {
+	var sm sitemap
+	if err = rsp.ToXML(&sm); err != nil {
+		return
+	}
+	for _, u := range sm.URLs {
+		urls = append(urls, strings.TrimSpace(u))
+	}
+	for _, u := range sm.Sitemaps {
+		sitemaps = append(sitemaps, strings.TrimSpace(u))
+	}
+	return
+}
+
+func (c *Crawler) visit(base *url.URL, link string) (u *url.URL, ok bool) {
+	ref, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	ref.Fragment = ""
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return
+	}
+	if c.Filter != nil && !c.Filter(ref) {
+		return
+	}
+	key := ref.String()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	return ref, true
+}
+
+func (c *Crawler) Run(ctx context.Context, fn CrawlFunc) error {
+	concurrency := c.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var frontier []*url.URL
+	for _, seed := range c.seeds {
+		if u, ok := c.visit(nil, seed); ok {
+			frontier = append(frontier, u)
+		}
+	}
+	for depth := 0; len(frontier) > 0 && depth <= c.MaxDepth; depth++ {
+		var next []*url.URL
+		for start := 0; start < len(frontier); start += concurrency {
+			end := start + concurrency
+			if end > len(frontier) {
+				end = len(frontier)
+			}
+			pages := map[*Request]*Page{}
+			var reqs []*Request
+			for _, u := range frontier[start:end] {
+				req := c.service.Request(http.MethodGet, u.String()).Limiter(c.limiter)
+				pages[req] = &Page{URL: u, Depth: depth}
+				reqs = append(reqs, req)
+			}
+			for result := range NewGroup(reqs...).AsyncContext(ctx) {
+				page := pages[result.Request]
+				page.Response, page.Err = result.Response, result.Err
+				for _, link := range fn(page) {
+					if u, ok := c.visit(page.URL, link); ok {
+						next = append(next, u)
+					}
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package httpr
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func (req *Request) CurlString(redact bool) (s string, err error) {
+	r, err := req.Request()
+	if err != nil {
+		return
+	}
+	header := r.Header
+	if redact {
+		header = req.redactor().header(r.Header)
+	}
+	parts := []string{"curl", "-X", r.Method}
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			parts = append(parts, "-H", shellQuote(key+": "+value))
+		}
+	}
+	if r.Host != "" && r.Host != r.URL.Host {
+		parts = append(parts, "-H", shellQuote("Host: "+r.Host))
+	}
+	body, replayable, err := requestBody(r)
+	if err != nil {
+		return
+	}
+	switch {
+	case !replayable:
+		parts = append(parts, "--data-binary", "@-")
+	case len(body) > 0:
+		if redact {
+			body = req.redactor().apply(body)
+		}
+		parts = append(parts, "--data-binary", shellQuote(string(body)))
+	}
+	if r.Header.Get("Accept-Encoding") != "" {
+		parts = append(parts, "--compressed")
+	}
+	u := *r.URL
+	if redact {
+		u.User = nil
+	}
+	parts = append(parts, shellQuote(u.String()))
+	s = strings.Join(parts, " ")
+	return
+}
+
+func (req *Request) debugCurl(rsp *Response, err error) {
+	if err == nil && (rsp == nil || rsp.StatusCode() < http.StatusBadRequest) {
+		return
+	}
+	if cmd, cerr := req.CurlString(true); cerr == nil {
+		defaultLogger.Errorf("%s\n", cmd)
+	}
+}
@@ -0,0 +1,163 @@
+package httpr
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	qpsWindow        = 10
+	recentErrorsSize = 16
+)
+
+type RecentError struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	URL    string    `json:"url"`
+	Status int       `json:"status,omitempty"`
+	Error  string    `json:"error"`
+}
+
+type HostHealth struct {
+	Host      string     `json:"host"`
+	Failures  int        `json:"failures"`
+	Ejected   bool       `json:"ejected"`
+	DownUntil *time.Time `json:"down_until,omitempty"`
+}
+
+type ServiceStats struct {
+	Name         string        `json:"name"`
+	Host         string        `json:"host"`
+	InFlight     int64         `json:"in_flight"`
+	Total        int64         `json:"total"`
+	Failed       int64         `json:"failed"`
+	QPS          float64       `json:"qps"`
+	Quota        *Quota        `json:"quota,omitempty"`
+	Hosts        []HostHealth  `json:"hosts,omitempty"`
+	RecentErrors []RecentError `json:"recent_errors,omitempty"`
+}
+
+type qpsBucket struct {
+	second int64
+	count  int64
+}
+
+type serviceStats struct {
+	inflight int64
+	total    int64
+	failed   int64
+
+	mu      sync.Mutex
+	buckets [qpsWindow]qpsBucket
+	errors  []RecentError
+	next    int
+}
+
+func (st *serviceStats) begin() {
+	atomic.AddInt64(&st.inflight, 1)
+	atomic.AddInt64(&st.total, 1)
+	now := time.Now().Unix()
+	st.mu.Lock()
+	b := &st.buckets[now%qpsWindow]
+	if b.second != now {
+		b.second, b.count = now, 0
+	}
+	b.count++
+	st.mu.Unlock()
+}
+
+func (st *serviceStats) end(req *Request, rsp *Response, err error) {
+	atomic.AddInt64(&st.inflight, -1)
+	if err == nil {
+		return
+	}
+	atomic.AddInt64(&st.failed, 1)
+	e := RecentError{Time: time.Now(), Method: req.method, URL: req.logURL(), Error: err.Error()}
+	if rsp != nil {
+		e.Status = rsp.StatusCode()
+	}
+	st.mu.Lock()
+	if len(st.errors) < recentErrorsSize {
+		st.errors = append(st.errors, e)
+	} else {
+		st.errors[st.next] = e
+	}
+	st.next = (st.next + 1) % recentErrorsSize
+	st.mu.Unlock()
+}
+
+func (st *serviceStats) qps(now int64) float64 {
+	var n int64
+	for _, b := range st.buckets {
+		if b.second > now-qpsWindow && b.second <= now {
+			n += b.count
+		}
+	}
+	return float64(n) / qpsWindow
+}
+
+func (s *Service) Stats() (stats ServiceStats) {
+	st := &s.stats
+	stats = ServiceStats{
+		Name:     s.name,
+		Host:     s.host,
+		InFlight: atomic.LoadInt64(&st.inflight),
+		Total:    atomic.LoadInt64(&st.total),
+		Failed:   atomic.LoadInt64(&st.failed),
+	}
+	if q := s.Quota(); q.Known() {
+		stats.Quota = &q
+	}
+	if s.balancer != nil {
+		stats.Hosts = s.balancer.health(time.Now())
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	stats.QPS = st.qps(time.Now().Unix())
+	for i := range st.errors {
+		j := (st.next - 1 - i + 2*recentErrorsSize) % recentErrorsSize
+		if j < len(st.errors) {
+			stats.RecentErrors = append(stats.RecentErrors, st.errors[j])
+		}
+	}
+	return
+}
+
+func (req *Request) track() func(rsp *Response, err error) {
+	if req.service == nil {
+		return func(*Response, error) {}
+	}
+	st := &req.service.stats
+	st.begin()
+	return func(rsp *Response, err error) {
+		st.end(req, rsp, err)
+	}
+}
+
+func DebugHandler(repo *Repo) http.Handler {
+	if repo == nil {
+		repo = DefaultRepo
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var stats []ServiceStats
+		name := r.URL.Query().Get("service")
+		repo.Range(func(key string, s *Service) bool {
+			if name == "" || name == key {
+				st := s.Stats()
+				st.Name = key
+				stats = append(stats, st)
+			}
+			return true
+		})
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(map[string]interface{}{
+			"time":     time.Now(),
+			"services": stats,
+		})
+	})
+}
@@ -0,0 +1,123 @@
+package httpr
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+type Decoder func(body []byte, obj interface{}) error
+
+var (
+	ErrUnsupportedMediaType = errors.New("httpr: unsupported media type")
+
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{
+		"application/json":                  json.Unmarshal,
+		"application/xml":                   xml.Unmarshal,
+		"text/xml":                          xml.Unmarshal,
+		"application/x-www-form-urlencoded": decodeForm,
+		"text/plain":                        decodeText,
+	}
+)
+
+func RegisterDecoder(mediaType string, decoder Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[strings.ToLower(mediaType)] = decoder
+}
+
+func lookupDecoder(contentType string) (Decoder, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	if d, ok := decoders[mediaType]; ok {
+		return d, true
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return decoders["application/json"], true
+	case strings.HasSuffix(mediaType, "+xml"):
+		return decoders["application/xml"], true
+	case strings.HasPrefix(mediaType, "text/"):
+		return decoders["text/plain"], true
+	}
+	return nil, false
+}
+
+func (rsp *Response) To(obj interface{}) (err error) {
+	contentType := rsp.rsp.Header.Get("Content-Type")
+	decoder, ok := lookupDecoder(contentType)
+	if !ok {
+		err = fmt.Errorf("%w: %q", ErrUnsupportedMediaType, contentType)
+		return
+	}
+	return rsp.cachedDecode(contentType, obj, func(bs []byte) error {
+		return decoder(bs, obj)
+	})
+}
+
+func (rsp *Response) ToAll(targets ...interface{}) (err error) {
+	contentType := rsp.rsp.Header.Get("Content-Type")
+	decoder, ok := lookupDecoder(contentType)
+	if !ok {
+		err = fmt.Errorf("%w: %q", ErrUnsupportedMediaType, contentType)
+		return
+	}
+	bs, err := rsp.decodable()
+	if err != nil {
+		return
+	}
+	for i, obj := range targets {
+		if err = decoder(bs, obj); err != nil {
+			err = fmt.Errorf("httpr: decode target %d (%T): %w", i, obj, err)
+			return
+		}
+	}
+	return
+}
+
+func decodeForm(body []byte, obj interface{}) error {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return err
+	}
+	switch v := obj.(type) {
+	case *url.Values:
+		*v = values
+	case *map[string][]string:
+		*v = values
+	case *map[string]string:
+		m := make(map[string]string, len(values))
+		for key := range values {
+			m[key] = values.Get(key)
+		}
+		*v = m
+	default:
+		return fmt.Errorf("httpr: cannot decode form into %T", obj)
+	}
+	return nil
+}
+
+func decodeText(body []byte, obj interface{}) error {
+	switch v := obj.(type) {
+	case *string:
+		*v = string(body)
+	case *[]byte:
+		*v = append((*v)[:0], body...)
+	case encoding.TextUnmarshaler:
+		return v.UnmarshalText(body)
+	default:
+		return fmt.Errorf("httpr: cannot decode text into %T", obj)
+	}
+	return nil
+}
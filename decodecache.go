@@ -0,0 +1,97 @@
+package httpr
+
+import "reflect"
+
+type decodedKey struct {
+	format string
+	typ    reflect.Type
+}
+
+func (s *Service) CacheDecoded() *Service {
+	s.cacheDecoded = true
+	return s
+}
+
+func (req *Request) CacheDecoded() *Request {
+	req.cacheDecoded = true
+	return req
+}
+
+func (rsp *Response) cachesDecoded() bool {
+	return rsp.req.cacheDecoded || (rsp.req.service != nil && rsp.req.service.cacheDecoded)
+}
+
+func (rsp *Response) cachedDecode(format string, obj interface{}, decode func(bs []byte) error) (err error) {
+	v := reflect.ValueOf(obj)
+	if !rsp.cachesDecoded() || v.Kind() != reflect.Ptr || v.IsNil() || !v.Elem().IsZero() {
+		bs, err := rsp.decodable()
+		if err != nil {
+			return err
+		}
+		return decode(bs)
+	}
+	key := decodedKey{format: format, typ: v.Type()}
+	rsp.decodedMu.Lock()
+	defer rsp.decodedMu.Unlock()
+	if cached, ok := rsp.decoded[key]; ok {
+		v.Elem().Set(deepCopy(cached))
+		return
+	}
+	bs, err := rsp.decodable()
+	if err != nil {
+		return
+	}
+	if err = decode(bs); err != nil {
+		return
+	}
+	if rsp.decoded == nil {
+		rsp.decoded = map[decodedKey]reflect.Value{}
+	}
+	rsp.decoded[key] = deepCopy(v.Elem())
+	return
+}
+
+func deepCopy(src reflect.Value) (dst reflect.Value) {
+	dst = reflect.New(src.Type()).Elem()
+	switch src.Kind() {
+	case reflect.Ptr:
+		if !src.IsNil() {
+			p := reflect.New(src.Type().Elem())
+			p.Elem().Set(deepCopy(src.Elem()))
+			dst.Set(p)
+		}
+	case reflect.Interface:
+		if !src.IsNil() {
+			dst.Set(deepCopy(src.Elem()))
+		}
+	case reflect.Slice:
+		if !src.IsNil() {
+			dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+			for i := 0; i < src.Len(); i++ {
+				dst.Index(i).Set(deepCopy(src.Index(i)))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(deepCopy(src.Index(i)))
+		}
+	case reflect.Map:
+		if !src.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+			iter := src.MapRange()
+			for iter.Next() {
+				dst.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+			}
+		}
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				dst.Field(i).Set(deepCopy(src.Field(i)))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+	return
+}
@@ -0,0 +1,147 @@
+package httpr
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type Receipt struct {
+	ID          string
+	URL         string
+	Attempts    int
+	Status      int
+	DeliveredAt time.Time
+	Err         error
+}
+
+type DeadLetterFunc func(url string, payload []byte, receipt Receipt)
+
+type Deliverer struct {
+	service         *Service
+	secret          []byte
+	signatureHeader string
+	timestampHeader string
+	idHeader        string
+	policy          *RetryPolicy
+	deadLetter      DeadLetterFunc
+}
+
+func NewDeliverer(s *Service, secret []byte) *Deliverer {
+	policy := DefaultRetryPolicy()
+	policy.MaxAttempts = 5
+	policy.BaseDelay = time.Second
+	policy.MaxDelay = time.Minute
+	policy.AllowNonIdempotent = true
+	policy.RetryOnStatus = []int{
+		http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+	return &Deliverer{
+		service:         s,
+		secret:          secret,
+		signatureHeader: "X-Signature",
+		timestampHeader: "X-Timestamp",
+		idHeader:        "X-Delivery-ID",
+		policy:          policy,
+	}
+}
+
+func (d *Deliverer) SignatureHeader(name string) *Deliverer {
+	d.signatureHeader = name
+	return d
+}
+
+func (d *Deliverer) Retry(policy *RetryPolicy) *Deliverer {
+	d.policy = policy
+	return d
+}
+
+func (d *Deliverer) DeadLetter(fn DeadLetterFunc) *Deliverer {
+	d.deadLetter = fn
+	return d
+}
+
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *Deliverer) Deliver(ctx context.Context, uri string, payload interface{}) (receipt Receipt, err error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	receipt = Receipt{
+		ID:  deliveryID(),
+		URL: uri,
+	}
+	for {
+		receipt.Attempts++
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		var rsp *Response
+		rsp, err = d.service.Post(uri).
+			Context(ctx).
+			Body(bytes.NewReader(body)).
+			RawHeader("Content-Type", "application/json").
+			RawHeader(d.idHeader, receipt.ID).
+			RawHeader(d.timestampHeader, timestamp).
+			RawHeader(d.signatureHeader, Sign(d.secret, timestamp, body)).
+			Response()
+		if rsp != nil {
+			receipt.Status = rsp.StatusCode()
+			discard(rsp)
+		}
+		if err == nil {
+			if receipt.Status >= 200 && receipt.Status < 300 {
+				receipt.DeliveredAt = time.Now()
+				return
+			}
+			err = fmt.Errorf("httpr: delivery %s rejected with status %d", receipt.ID, receipt.Status)
+		}
+		if ctx.Err() != nil || d.policy == nil || receipt.Attempts >= d.policy.attempts() || !d.retryable(rsp, err) {
+			break
+		}
+		if err = sleep(ctx, d.policy.delay(receipt.Attempts, rsp)); err != nil {
+			break
+		}
+	}
+	receipt.Err = err
+	if d.deadLetter != nil {
+		d.deadLetter(uri, body, receipt)
+	}
+	return
+}
+
+func (d *Deliverer) retryable(rsp *Response, err error) bool {
+	if rsp == nil {
+		return classMatches(d.policy.RetryOn, ClassOf(err))
+	}
+	for _, code := range d.policy.RetryOnStatus {
+		if rsp.StatusCode() == code {
+			return true
+		}
+	}
+	return false
+}
+
+func deliveryID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,132 @@
+package httpr
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type DiagnosticStep struct {
+	Name     string        `json:"name"`
+	OK       bool          `json:"ok"`
+	Duration time.Duration `json:"duration"`
+	Detail   string        `json:"detail,omitempty"`
+	Err      string        `json:"error,omitempty"`
+}
+
+type Diagnosis struct {
+	URL      string           `json:"url"`
+	Steps    []DiagnosticStep `json:"steps"`
+	FailedAt string           `json:"failed_at,omitempty"`
+}
+
+func (d *Diagnosis) OK() bool {
+	return d.FailedAt == ""
+}
+
+func (d *Diagnosis) step(name string, start time.Time, detail string, err error) bool {
+	step := DiagnosticStep{
+		Name:     name,
+		OK:       err == nil,
+		Duration: time.Since(start),
+		Detail:   detail,
+	}
+	if err != nil {
+		step.Err = err.Error()
+		d.FailedAt = name
+	}
+	d.Steps = append(d.Steps, step)
+	return err == nil
+}
+
+func (s *Service) Diagnose(ctx context.Context, rawurl string) (d *Diagnosis, err error) {
+	if !isAbsoluteURL(rawurl) {
+		rawurl = s.host + rawurl
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return
+	}
+	d = &Diagnosis{URL: u.Redacted()}
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	start := time.Now()
+	var addrs []string
+	if ip := net.ParseIP(host); ip != nil {
+		addrs = []string{host}
+	} else if s.resolver != nil {
+		addrs, err = s.resolver.LookupHost(ctx, host)
+	} else {
+		addrs, err = net.DefaultResolver.LookupHost(ctx, host)
+	}
+	if !d.step("dns", start, strings.Join(addrs, ", "), err) {
+		err = nil
+		return
+	}
+
+	start = time.Now()
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	for _, addr := range addrs {
+		if conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(addr, port)); err == nil {
+			break
+		}
+	}
+	detail := ""
+	if conn != nil {
+		detail = conn.RemoteAddr().String()
+	}
+	if !d.step("tcp", start, detail, err) {
+		err = nil
+		return
+	}
+	defer conn.Close()
+
+	if u.Scheme == "https" {
+		start = time.Now()
+		conf := &tls.Config{}
+		if t, ok := s.client.Transport.(*http.Transport); ok && t.TLSClientConfig != nil {
+			conf = t.TLSClientConfig.Clone()
+		}
+		if conf.ServerName == "" {
+			conf.ServerName = host
+		}
+		tc := tls.Client(conn, conf)
+		err = tc.HandshakeContext(ctx)
+		detail = ""
+		if err == nil {
+			state := tc.ConnectionState()
+			detail = fmt.Sprintf("%s, %s", tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite))
+			if len(state.PeerCertificates) > 0 {
+				cert := state.PeerCertificates[0]
+				detail += fmt.Sprintf(", subject %s, expires %s", cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339))
+			}
+		}
+		if !d.step("tls", start, detail, err) {
+			err = nil
+			return
+		}
+	}
+
+	start = time.Now()
+	rsp, err := s.Request(http.MethodGet, u.String()).Context(ctx).Response()
+	detail = ""
+	if err == nil {
+		detail = fmt.Sprintf("%s %d", rsp.rsp.Proto, rsp.StatusCode())
+		discard(rsp)
+	}
+	d.step("http", start, detail, err)
+	err = nil
+	return
+}
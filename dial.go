@@ -0,0 +1,30 @@
+package httpr
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func (s *Service) DialContext(dial DialFunc) *Service {
+	s.dialer = dial
+	s.transport().DialContext = s.dialContext
+	return s
+}
+
+func (s *Service) UnixSocket(path string) *Service {
+	s.DialContext(func(ctx context.Context, _, _ string) (net.Conn, error) {
+		d := net.Dialer{Timeout: 30 * time.Second}
+		if s.dialTimeout > 0 {
+			d.Timeout = s.dialTimeout
+		}
+		return d.DialContext(ctx, "unix", path)
+	})
+	s.transport().Proxy = nil
+	if s.host == "" {
+		s.host = "http://unix"
+	}
+	return s
+}
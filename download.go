@@ -0,0 +1,306 @@
+package httpr
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultMinChunk = 1 << 20
+
+var ErrChecksumMismatch = errors.New("httpr: checksum mismatch")
+
+type DownloadOption func(*downloadConfig)
+
+type downloadConfig struct {
+	resume    bool
+	parallel  int
+	minChunk  int64
+	progress  []ProgressFunc
+	algorithm string
+	newHash   func() hash.Hash
+	checksum  string
+	err       error
+	ctx       context.Context
+}
+
+func Resume() DownloadOption {
+	return func(c *downloadConfig) {
+		c.resume = true
+	}
+}
+
+func Parallel(n int, minChunk int64) DownloadOption {
+	return func(c *downloadConfig) {
+		if n < 1 {
+			c.err = fmt.Errorf("httpr: invalid parallel download count %d", n)
+			return
+		}
+		if minChunk <= 0 {
+			minChunk = defaultMinChunk
+		}
+		c.parallel = n
+		c.minChunk = minChunk
+	}
+}
+
+func Progress(fn ProgressFunc) DownloadOption {
+	return func(c *downloadConfig) {
+		c.progress = append(c.progress, fn)
+	}
+}
+
+func SHA256(sum string) DownloadOption {
+	return func(c *downloadConfig) {
+		c.algorithm, c.newHash, c.checksum = "sha256", sha256.New, strings.ToLower(sum)
+	}
+}
+
+func MD5(sum string) DownloadOption {
+	return func(c *downloadConfig) {
+		c.algorithm, c.newHash, c.checksum = "md5", md5.New, strings.ToLower(sum)
+	}
+}
+
+func (req *Request) Download(path string, opts ...DownloadOption) (err error) {
+	return req.DownloadContext(req.context(), path, opts...)
+}
+
+func (req *Request) DownloadContext(ctx context.Context, path string, opts ...DownloadOption) (err error) {
+	conf := downloadConfig{minChunk: defaultMinChunk, ctx: ctx}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	if conf.err != nil {
+		return conf.err
+	}
+	if conf.parallel > 1 {
+		var handled bool
+		if handled, err = req.downloadParallel(path, &conf); handled || err != nil {
+			return
+		}
+	}
+	if err = req.downloadSerial(path, &conf); err != nil {
+		return
+	}
+	return conf.verify(path)
+}
+
+func (req *Request) rangeRequest(header string) *Request {
+	r := req.Clone()
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+	r.header.Set("Range", header)
+	return r
+}
+
+func (req *Request) downloadSerial(path string, conf *downloadConfig) (err error) {
+	var offset int64
+	if conf.resume {
+		if fi, serr := os.Stat(path); serr == nil {
+			offset = fi.Size()
+		}
+	}
+	r := req
+	if offset > 0 {
+		r = req.rangeRequest(fmt.Sprintf("bytes=%d-", offset))
+	}
+	rsp, err := r.Do(conf.ctx)
+	if err != nil {
+		return
+	}
+	defer rsp.Close()
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	total := rsp.rsp.ContentLength
+	switch code := rsp.StatusCode(); {
+	case code == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		if contentRangeTotal(rsp.Header().Get("Content-Range")) == offset {
+			return
+		}
+		rsp.Close()
+		fresh := *conf
+		fresh.resume = false
+		return req.downloadSerial(path, &fresh)
+	case code == http.StatusPartialContent && offset > 0:
+		flags = os.O_WRONLY | os.O_APPEND
+		if total >= 0 {
+			total += offset
+		}
+	case code >= 200 && code < 300:
+		offset = 0
+	default:
+		return rsp.statusError()
+	}
+	body, err := rsp.Reader()
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return
+	}
+	var src io.Reader = body
+	if len(conf.progress) > 0 {
+		src = &progressReader{r: body, read: offset, total: total, progress: conf.progress}
+	}
+	_, err = io.Copy(f, src)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return
+}
+
+func contentRangeTotal(value string) int64 {
+	i := strings.LastIndexByte(value, '/')
+	if i < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(value[i+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+func (req *Request) downloadParallel(path string, conf *downloadConfig) (handled bool, err error) {
+	if conf.resume {
+		if fi, serr := os.Stat(path); serr == nil && fi.Size() > 0 {
+			return
+		}
+	}
+	probe, err := req.rangeRequest("bytes=0-0").Do(conf.ctx)
+	if err != nil {
+		return
+	}
+	discard(probe)
+	total := contentRangeTotal(probe.Header().Get("Content-Range"))
+	if probe.StatusCode() != http.StatusPartialContent || total < 2*conf.minChunk {
+		return
+	}
+	handled = true
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return
+	}
+	if err = f.Truncate(total); err != nil {
+		f.Close()
+		os.Remove(path)
+		return
+	}
+	chunks := int64(conf.parallel)
+	if max := total / conf.minChunk; chunks > max {
+		chunks = max
+	}
+	size := (total + chunks - 1) / chunks
+	var (
+		read int64
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for start := int64(0); start < total; start += size {
+		end := start + size - 1
+		if end >= total {
+			end = total - 1
+		}
+		wg.Add(1)
+		go func(start, end int64) {
+			defer wg.Done()
+			if cerr := req.downloadChunk(f, start, end, total, &read, conf); cerr != nil {
+				mu.Lock()
+				errs = append(errs, cerr)
+				mu.Unlock()
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	err = errors.Join(errs...)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = conf.verify(path)
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return
+}
+
+func (req *Request) downloadChunk(f *os.File, start, end, total int64, read *int64, conf *downloadConfig) (err error) {
+	rsp, err := req.rangeRequest(fmt.Sprintf("bytes=%d-%d", start, end)).Do(conf.ctx)
+	if err != nil {
+		return
+	}
+	defer rsp.Close()
+	if rsp.StatusCode() != http.StatusPartialContent {
+		return rsp.statusError()
+	}
+	body, err := rsp.Reader()
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	buf := make([]byte, 32<<10)
+	offset := start
+	for offset <= end {
+		n, rerr := body.Read(buf)
+		if n > 0 {
+			if int64(n) > end-offset+1 {
+				n = int(end - offset + 1)
+			}
+			if _, err = f.WriteAt(buf[:n], offset); err != nil {
+				return
+			}
+			offset += int64(n)
+			done := atomic.AddInt64(read, int64(n))
+			for _, fn := range conf.progress {
+				fn(done, total)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+	if offset <= end {
+		err = fmt.Errorf("httpr: short read for range %d-%d", start, end)
+	}
+	return
+}
+
+func (c *downloadConfig) verify(path string) (err error) {
+	if c.newHash == nil {
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	h := c.newHash()
+	_, err = io.Copy(h, f)
+	f.Close()
+	if err != nil {
+		return
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != c.checksum {
+		os.Remove(path)
+		err = fmt.Errorf("%w: %s %s, expected %s", ErrChecksumMismatch, c.algorithm, sum, c.checksum)
+	}
+	return
+}
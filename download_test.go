@@ -0,0 +1,59 @@
+package httpr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadParallel(t *testing.T) {
+	data := strings.Repeat("0123456789", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "data", time.Time{}, strings.NewReader(data))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "data")
+	if err := NewRequest(http.MethodGet, server.URL).Download(path, Parallel(4, 1000)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != data {
+		t.Errorf("downloaded %d bytes, want %d matching bytes", len(got), len(data))
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	data := strings.Repeat("0123456789", 100)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "data", time.Time{}, strings.NewReader(data))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, []byte(data[:300]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewRequest(http.MethodGet, server.URL).Download(path, Resume()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != data {
+		t.Errorf("downloaded %d bytes, want %d matching bytes", len(got), len(data))
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=300-" {
+		t.Errorf("ranges = %q, want [bytes=300-]", ranges)
+	}
+}
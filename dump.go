@@ -0,0 +1,153 @@
+package httpr
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+type redactor struct {
+	headers map[string]bool
+	params  map[string]bool
+	body    []func(body []byte) []byte
+}
+
+func newRedactor() *redactor {
+	r := &redactor{headers: map[string]bool{}, params: map[string]bool{}}
+	r.add("Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie")
+	r.addParams("access_token", "id_token", "refresh_token", "token", "api_key", "apikey", "key", "password",
+		"secret", "client_secret", "signature", "sig", "X-Amz-Signature", "X-Amz-Credential", "X-Amz-Security-Token")
+	return r
+}
+
+func (r *redactor) addParams(names ...string) {
+	for _, name := range names {
+		r.params[strings.ToLower(name)] = true
+	}
+}
+
+func (r *redactor) url(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Redacted()
+	}
+	parts := strings.Split(u.RawQuery, "&")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if name, err := url.QueryUnescape(key); err == nil && r.params[strings.ToLower(name)] {
+			parts[i] = key + "=" + redacted
+		}
+	}
+	dup := *u
+	dup.RawQuery = strings.Join(parts, "&")
+	return dup.Redacted()
+}
+
+func (r *redactor) rawURL(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	return r.url(u)
+}
+
+func (r *redactor) add(headers ...string) {
+	for _, h := range headers {
+		r.headers[http.CanonicalHeaderKey(h)] = true
+	}
+}
+
+func (r *redactor) header(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for key, values := range h {
+		if r.headers[http.CanonicalHeaderKey(key)] {
+			values = []string{redacted}
+		}
+		out[key] = append([]string(nil), values...)
+	}
+	return out
+}
+
+func (r *redactor) apply(bs []byte) []byte {
+	for _, fn := range r.body {
+		bs = fn(bs)
+	}
+	return bs
+}
+
+var defaultRedactor = newRedactor()
+
+func (s *Service) redactor() *redactor {
+	if s.redact == nil {
+		s.redact = newRedactor()
+	}
+	return s.redact
+}
+
+func (s *Service) RedactHeaders(headers ...string) *Service {
+	s.redactor().add(headers...)
+	return s
+}
+
+func (s *Service) RedactParams(params ...string) *Service {
+	s.redactor().addParams(params...)
+	return s
+}
+
+func (s *Service) RedactBody(rules ...func(body []byte) []byte) *Service {
+	r := s.redactor()
+	r.body = append(r.body, rules...)
+	return s
+}
+
+func RedactRegexp(pattern string) func(body []byte) []byte {
+	re := regexp.MustCompile(pattern)
+	return func(body []byte) []byte {
+		return re.ReplaceAll(body, []byte(redacted))
+	}
+}
+
+func (req *Request) redactor() *redactor {
+	if req.service != nil && req.service.redact != nil {
+		return req.service.redact
+	}
+	return defaultRedactor
+}
+
+func (req *Request) dumpable() (r *http.Request, err error) {
+	r = req.req.Clone(req.req.Context())
+	r.Header = req.redactor().header(req.req.Header)
+	r.Body = nil
+	if req.req.GetBody != nil {
+		r.Body, err = req.req.GetBody()
+	}
+	return
+}
+
+func (rsp *Response) dumpable() (r *http.Response, err error) {
+	body, err := rsp.Bytes()
+	if err != nil {
+		return
+	}
+	dup := *rsp.rsp
+	dup.Header = rsp.req.redactor().header(rsp.rsp.Header)
+	dup.Body = ioutil.NopCloser(bytes.NewReader(body))
+	dup.ContentLength = int64(len(body))
+	dup.TransferEncoding = nil
+	r = &dup
+	return
+}
+
+func (req *Request) debug(rsp *Response, err error) {
+	prefix := ""
+	if id, ok := req.tags[GroupIDTag]; ok {
+		prefix = "[group=" + id + "] "
+	}
+	if err != nil {
+		defaultLogger.Errorf("%s%s %s: %v\n", prefix, req.method, req.uri, err)
+		return
+	}
+	defaultLogger.Infof("%s%s\n", prefix, rsp.Dump())
+}
@@ -0,0 +1,189 @@
+package httpr
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type ContentDecoder func(r io.Reader) (io.ReadCloser, error)
+
+type ContentEncoder func(w io.Writer) (io.WriteCloser, error)
+
+var (
+	contentDecodersMu sync.RWMutex
+	contentDecoders   = map[string]ContentDecoder{
+		"gzip": func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		"deflate": inflate,
+	}
+)
+
+func RegisterContentEncoding(name string, decoder ContentDecoder) {
+	contentDecodersMu.Lock()
+	defer contentDecodersMu.Unlock()
+	contentDecoders[strings.ToLower(name)] = decoder
+}
+
+func (s *Service) ContentEncoding(name string, decoder ContentDecoder) *Service {
+	if s.contentDecoders == nil {
+		s.contentDecoders = map[string]ContentDecoder{}
+	}
+	s.contentDecoders[strings.ToLower(name)] = decoder
+	return s
+}
+
+func (s *Service) CompressWith(name string, encoder ContentEncoder) *Service {
+	s.compressName = name
+	s.compressor = encoder
+	return s
+}
+
+func (req *Request) contentDecoder(name string) (ContentDecoder, bool) {
+	if req.service != nil {
+		if d, ok := req.service.contentDecoders[strings.ToLower(strings.TrimSpace(name))]; ok {
+			return d, true
+		}
+	}
+	return contentDecoder(name)
+}
+
+func (s *Service) acceptedEncodings() string {
+	if s.acceptEncoding != "*" {
+		return s.acceptEncoding
+	}
+	names := ContentEncodings()
+	for name := range s.contentDecoders {
+		if _, ok := contentDecoder(name); !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func contentDecoder(name string) (ContentDecoder, bool) {
+	contentDecodersMu.RLock()
+	defer contentDecodersMu.RUnlock()
+	d, ok := contentDecoders[strings.ToLower(strings.TrimSpace(name))]
+	return d, ok
+}
+
+func ContentEncodings() (names []string) {
+	contentDecodersMu.RLock()
+	defer contentDecodersMu.RUnlock()
+	for name := range contentDecoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+func inflate(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(2)
+	if len(head) == 2 && head[0]&0x0f == 8 && (uint16(head[0])<<8|uint16(head[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+func (s *Service) AcceptEncoding(encodings ...string) *Service {
+	if len(encodings) == 0 {
+		s.acceptEncoding = "*"
+		return s
+	}
+	s.acceptEncoding = strings.Join(encodings, ", ")
+	return s
+}
+
+func (req *Request) Compress() *Request {
+	req.compress = true
+	return req
+}
+
+func (req *Request) compressBody() (err error) {
+	if !req.compress || req.body == nil {
+		return
+	}
+	name, encoder := "gzip", ContentEncoder(func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	})
+	if req.service != nil && req.service.compressor != nil {
+		name, encoder = req.service.compressName, req.service.compressor
+	}
+	var buf bytes.Buffer
+	w, err := encoder(&buf)
+	if err != nil {
+		return
+	}
+	if _, err = io.Copy(w, req.body); err != nil {
+		return
+	}
+	if err = w.Close(); err != nil {
+		return
+	}
+	req.body = bytes.NewReader(buf.Bytes())
+	req.compress = false
+	req.compressed = name
+	return
+}
+
+func (req *Request) negotiateEncoding(r *http.Request) {
+	if req.compressed != "" && r.Header.Get("Content-Encoding") == "" {
+		r.Header.Set("Content-Encoding", req.compressed)
+	}
+	if req.service == nil || req.service.acceptEncoding == "" || r.Header.Get("Accept-Encoding") != "" {
+		return
+	}
+	r.Header.Set("Accept-Encoding", req.service.acceptedEncodings())
+	req.decodeContent = true
+}
+
+type lazyDecoder struct {
+	body    io.ReadCloser
+	decoder ContentDecoder
+	r       io.ReadCloser
+	err     error
+}
+
+func (d *lazyDecoder) Read(p []byte) (int, error) {
+	if d.r == nil && d.err == nil {
+		d.r, d.err = d.decoder(d.body)
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.r.Read(p)
+}
+
+func (d *lazyDecoder) Close() error {
+	if d.r != nil {
+		d.r.Close()
+	}
+	return d.body.Close()
+}
+
+func (req *Request) decodeResponse(r *http.Request, resp *http.Response) {
+	if !req.decodeContent || r.Method == http.MethodHead || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return
+	}
+	encoding := resp.Header.Get("Content-Encoding")
+	decoder, ok := req.contentDecoder(encoding)
+	if encoding == "" || !ok {
+		return
+	}
+	resp.Body = &lazyDecoder{body: resp.Body, decoder: decoder}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
@@ -0,0 +1,34 @@
+package httpr
+
+type ErrorDecoder func(status int, body []byte) error
+
+func (s *Service) ErrorDecoder(decoder ErrorDecoder) *Service {
+	s.errorDecoder = decoder
+	return s
+}
+
+func (rsp *Response) decodeError() (err error) {
+	if rsp.StatusCode() < 400 {
+		return
+	}
+	if rsp.req.service == nil {
+		return
+	}
+	if rsp.req.service.errorDecoder == nil {
+		if !rsp.req.service.decodeProblems {
+			return
+		}
+		if p, ok := rsp.Problem(); ok {
+			rsp.fail = p
+			err = p
+		}
+		return
+	}
+	bs, err := rsp.Bytes()
+	if err != nil {
+		return
+	}
+	rsp.fail = rsp.req.service.errorDecoder(rsp.StatusCode(), bs)
+	err = rsp.fail
+	return
+}
@@ -0,0 +1,122 @@
+package httpr
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+var (
+	ErrUnpairedParams = errors.New("httpr: params are not key/value pairs")
+	ErrUnpairedPaths  = errors.New("httpr: paths are not key/path pairs")
+	ErrPathNotFound   = errors.New("httpr: path not found")
+	ErrInvalidHost    = errors.New("httpr: invalid host")
+	ErrRequestReused  = errors.New("httpr: request already executed; use Clone for each execution")
+	ErrBodyNotCloned  = errors.New("httpr: request body cannot be cloned")
+	ErrInvalidAddress = errors.New("httpr: invalid resolve address")
+	ErrNilContext     = errors.New("httpr: nil context")
+)
+
+type ErrorClass int
+
+const (
+	ClassUnknown ErrorClass = iota
+	ClassDNS
+	ClassRefused
+	ClassReset
+	ClassTLS
+	ClassTimeout
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ClassDNS:
+		return "dns"
+	case ClassRefused:
+		return "refused"
+	case ClassReset:
+		return "reset"
+	case ClassTLS:
+		return "tls"
+	case ClassTimeout:
+		return "timeout"
+	}
+	return "unknown"
+}
+
+type Classifier func(err error) ErrorClass
+
+type TransportError struct {
+	Class ErrorClass
+	Err   error
+}
+
+func (e *TransportError) Error() string {
+	return e.Class.String() + ": " + e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+func ClassOf(err error) ErrorClass {
+	var te *TransportError
+	if errors.As(err, &te) {
+		return te.Class
+	}
+	return ClassUnknown
+}
+
+func DefaultClassifier(err error) ErrorClass {
+	var (
+		dnsErr      *net.DNSError
+		unknownCA   x509.UnknownAuthorityError
+		invalidCert x509.CertificateInvalidError
+		hostnameErr x509.HostnameError
+		recordErr   tls.RecordHeaderError
+		alertErr    tls.AlertError
+		opErr       *net.OpError
+		verifyErr   *tls.CertificateVerificationError
+		netErr      net.Error
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		return ClassDNS
+	case errors.As(err, &unknownCA), errors.As(err, &invalidCert), errors.As(err, &hostnameErr),
+		errors.As(err, &recordErr), errors.As(err, &alertErr), errors.As(err, &verifyErr),
+		errors.As(err, &opErr) && opErr.Op == "remote error":
+		return ClassTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ClassRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return ClassReset
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ClassTimeout
+	}
+	return ClassUnknown
+}
+
+func (s *Service) Classifier(c Classifier) *Service {
+	s.classifier = c
+	return s
+}
+
+func (req *Request) RetryOn(classes ...ErrorClass) *Request {
+	req.retryOn = classes
+	return req
+}
+
+func (req *Request) classify(err error) error {
+	classifier := DefaultClassifier
+	if req.service != nil && req.service.classifier != nil {
+		classifier = req.service.classifier
+	}
+	return &TransportError{
+		Class: classifier(err),
+		Err:   err,
+	}
+}
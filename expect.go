@@ -0,0 +1,142 @@
+package httpr
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const errorSnippetSize = 512
+
+type ContentTypeError struct {
+	Expected    []string
+	ContentType string
+	StatusCode  int
+	Snippet     string
+}
+
+func (e *ContentTypeError) Error() string {
+	got := e.ContentType
+	if got == "" {
+		got = "none"
+	}
+	return fmt.Sprintf("httpr: unexpected content type %s (status %d), expected %s", got, e.StatusCode, strings.Join(e.Expected, " or "))
+}
+
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	URL        string
+	Snippet    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("httpr: %s %s: unexpected status %s", e.Method, e.URL, e.Status)
+}
+
+func (req *Request) ExpectSuccess() *Request {
+	req.expectSuccess = true
+	return req
+}
+
+func (req *Request) ExpectStatus(codes ...int) *Request {
+	req.expectStatus = append(req.expectStatus, codes...)
+	return req
+}
+
+func (rsp *Response) ExpectStatus(codes ...int) error {
+	code := rsp.StatusCode()
+	for _, c := range codes {
+		if c == code {
+			return nil
+		}
+	}
+	return rsp.failure()
+}
+
+func (rsp *Response) ExpectSuccess() error {
+	if code := rsp.StatusCode(); code >= 200 && code < 300 {
+		return nil
+	}
+	return rsp.failure()
+}
+
+func (rsp *Response) statusError() *StatusError {
+	e := &StatusError{
+		StatusCode: rsp.rsp.StatusCode,
+		Status:     rsp.rsp.Status,
+		Snippet:    rsp.snippet(),
+	}
+	if e.Status == "" {
+		e.Status = fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	if r := rsp.rsp.Request; r != nil {
+		e.Method, e.URL = r.Method, rsp.req.redactor().url(r.URL)
+	}
+	return e
+}
+
+func (req *Request) ExpectContentType(mediaTypes ...string) *Request {
+	req.expectTypes = append(req.expectTypes, mediaTypes...)
+	return req
+}
+
+func (req *Request) ExpectJSON() *Request {
+	return req.ExpectContentType("application/json", "*/*+json")
+}
+
+func mediaTypeMatches(pattern, mediaType string) bool {
+	pattern = strings.ToLower(pattern)
+	if pattern == mediaType || pattern == "*/*" {
+		return true
+	}
+	if strings.HasPrefix(pattern, "*/*+") {
+		return strings.HasSuffix(mediaType, pattern[3:])
+	}
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(mediaType, pattern[:len(pattern)-1])
+	}
+	return false
+}
+
+func (req *Request) expect(rsp *Response) (err error) {
+	if req.expectSuccess {
+		if err = rsp.ExpectSuccess(); err != nil {
+			return
+		}
+	}
+	if len(req.expectStatus) > 0 {
+		if err = rsp.ExpectStatus(req.expectStatus...); err != nil {
+			return
+		}
+	}
+	if len(req.expectTypes) == 0 {
+		return
+	}
+	contentType := rsp.Header().Get("Content-Type")
+	mediaType, _, perr := mime.ParseMediaType(contentType)
+	if perr == nil {
+		for _, pattern := range req.expectTypes {
+			if mediaTypeMatches(pattern, mediaType) {
+				return
+			}
+		}
+	}
+	err = &ContentTypeError{
+		Expected:    req.expectTypes,
+		ContentType: contentType,
+		StatusCode:  rsp.StatusCode(),
+		Snippet:     rsp.snippet(),
+	}
+	return
+}
+
+func (rsp *Response) snippet() string {
+	bs, _ := rsp.Bytes()
+	if len(bs) > errorSnippetSize {
+		bs = bs[:errorSnippetSize]
+	}
+	return string(bs)
+}
@@ -0,0 +1,144 @@
+package httpr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type FetchState struct {
+	ETag         string
+	LastModified string
+	UseHead      bool
+}
+
+func (s *FetchState) empty() bool {
+	return s.ETag == "" && s.LastModified == ""
+}
+
+func (s *FetchState) update(h http.Header) {
+	s.ETag = h.Get("ETag")
+	s.LastModified = h.Get("Last-Modified")
+}
+
+func (s *FetchState) matches(h http.Header) bool {
+	if s.ETag != "" {
+		return s.ETag == h.Get("ETag")
+	}
+	return s.LastModified != "" && s.LastModified == h.Get("Last-Modified")
+}
+
+func (req *Request) FetchIfChanged(state *FetchState) (rsp *Response, notModified bool, err error) {
+	if state.UseHead && !state.empty() {
+		head := req.Clone()
+		head.method = http.MethodHead
+		head.body = nil
+		var hrsp *Response
+		hrsp, err = head.Response()
+		if err != nil {
+			return
+		}
+		discard(hrsp)
+		if hrsp.StatusCode() == http.StatusOK && state.matches(hrsp.rsp.Header) {
+			notModified = true
+			return
+		}
+	}
+	if !state.empty() {
+		etag, lastModified := state.ETag, state.LastModified
+		req.BeforeRequest(func(r *http.Request) {
+			if etag != "" {
+				r.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				r.Header.Set("If-Modified-Since", lastModified)
+			}
+		})
+	}
+	rsp, err = req.Response()
+	if err != nil {
+		return
+	}
+	if rsp.StatusCode() == http.StatusNotModified {
+		discard(rsp)
+		notModified = true
+		return
+	}
+	if rsp.StatusCode() >= 200 && rsp.StatusCode() < 300 {
+		state.update(rsp.rsp.Header)
+	}
+	return
+}
+
+func (req *Request) IfNoneMatch(etags ...string) *Request {
+	return req.RawHeader("If-None-Match", strings.Join(etags, ", "))
+}
+
+func (req *Request) IfModifiedSince(t time.Time) *Request {
+	return req.RawHeader("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+func (rsp *Response) ETag() string {
+	return rsp.Header().Get("ETag")
+}
+
+func (rsp *Response) LastModified() (t time.Time) {
+	t, _ = http.ParseTime(rsp.Header().Get("Last-Modified"))
+	return
+}
+
+func (rsp *Response) NotModified() bool {
+	return rsp.StatusCode() == http.StatusNotModified
+}
+
+var ErrPreconditionFailed = errors.New("httpr: precondition failed")
+
+type PreconditionError struct {
+	Method  string
+	URL     string
+	IfMatch string
+	Current string
+}
+
+func (e *PreconditionError) Error() string {
+	msg := fmt.Sprintf("httpr: %s %s: precondition failed", e.Method, e.URL)
+	if e.IfMatch != "" {
+		msg += fmt.Sprintf(" (If-Match %s", e.IfMatch)
+		if e.Current != "" {
+			msg += ", current " + e.Current
+		}
+		msg += ")"
+	}
+	return msg
+}
+
+func (e *PreconditionError) Is(target error) bool {
+	return target == ErrPreconditionFailed
+}
+
+func (req *Request) IfMatch(etags ...string) *Request {
+	return req.RawHeader("If-Match", strings.Join(etags, ", "))
+}
+
+func (req *Request) IfUnmodifiedSince(t time.Time) *Request {
+	return req.RawHeader("If-Unmodified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+func (rsp *Response) precondition() error {
+	r := rsp.rsp.Request
+	if rsp.StatusCode() != http.StatusPreconditionFailed || r == nil || !conditional(r.Header) {
+		return nil
+	}
+	return &PreconditionError{
+		Method:  r.Method,
+		URL:     rsp.req.redactor().url(r.URL),
+		IfMatch: r.Header.Get("If-Match"),
+		Current: rsp.ETag(),
+	}
+}
+
+func conditional(h http.Header) bool {
+	return h.Get("If-Match") != "" || h.Get("If-Unmodified-Since") != "" || h.Get("If-None-Match") != ""
+}
@@ -0,0 +1,80 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+var DefaultForwardHeaders = []string{
+	"Traceparent",
+	"Tracestate",
+	"Baggage",
+	"X-Request-Id",
+	"X-Correlation-Id",
+	"X-B3-*",
+	"Accept-Language",
+}
+
+type inboundKey struct{}
+
+func WithInbound(ctx context.Context, in *http.Request) context.Context {
+	return context.WithValue(ctx, inboundKey{}, in.Header)
+}
+
+func ForwardHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithInbound(r.Context(), r)))
+	})
+}
+
+func inboundHeader(ctx context.Context) http.Header {
+	h, _ := ctx.Value(inboundKey{}).(http.Header)
+	return h
+}
+
+func allowed(key string, allow []string) bool {
+	for _, pattern := range allow {
+		pattern = http.CanonicalHeaderKey(pattern)
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(key, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+			continue
+		}
+		if key == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func forward(dst, src http.Header, allow []string) {
+	if len(allow) == 0 {
+		allow = DefaultForwardHeaders
+	}
+	for key, values := range src {
+		key = http.CanonicalHeaderKey(key)
+		if _, set := dst[key]; set || !allowed(key, allow) {
+			continue
+		}
+		dst[key] = append([]string(nil), values...)
+	}
+}
+
+func (s *Service) Forward(allow ...string) *Service {
+	s.beforeRequest = append(s.beforeRequest, func(r *http.Request) {
+		if in := inboundHeader(r.Context()); in != nil {
+			forward(r.Header, in, allow)
+		}
+	})
+	return s
+}
+
+func (req *Request) ForwardFrom(in *http.Request, allow ...string) *Request {
+	if req.header == nil {
+		req.header = http.Header{}
+	}
+	forward(req.header, in.Header, allow)
+	return req
+}
@@ -0,0 +1,85 @@
+package httpr
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func cacheControl(h http.Header) map[string]string {
+	directives := map[string]string{}
+	for _, line := range h.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			key, value, _ := strings.Cut(part, "=")
+			directives[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return directives
+}
+
+func (rsp *Response) Freshness() (ttl time.Duration, ok bool) {
+	return rsp.freshness(time.Now())
+}
+
+func (rsp *Response) freshness(now time.Time) (ttl time.Duration, ok bool) {
+	return freshness(rsp.Header(), rsp.req.startAt, rsp.req.endAt, now)
+}
+
+func freshness(h http.Header, requestTime, responseTime, now time.Time) (ttl time.Duration, ok bool) {
+	cc := cacheControl(h)
+	if _, noStore := cc["no-store"]; noStore {
+		return 0, true
+	}
+	if _, noCache := cc["no-cache"]; noCache {
+		return 0, true
+	}
+	date, err := http.ParseTime(h.Get("Date"))
+	hasDate := err == nil
+	var lifetime time.Duration
+	if v, found := cc["max-age"]; found {
+		secs, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, true
+		}
+		lifetime = time.Duration(secs) * time.Second
+	} else if expires := h.Get("Expires"); expires != "" {
+		exp, err := http.ParseTime(expires)
+		if err != nil {
+			return 0, true
+		}
+		base := now
+		if hasDate {
+			base = date
+		}
+		lifetime = exp.Sub(base)
+	} else {
+		return 0, false
+	}
+
+	if responseTime.IsZero() {
+		responseTime = now
+	}
+	if requestTime.IsZero() {
+		requestTime = responseTime
+	}
+	var age time.Duration
+	if hasDate && responseTime.After(date) {
+		age = responseTime.Sub(date)
+	}
+	if secs, err := strconv.ParseInt(h.Get("Age"), 10, 64); err == nil {
+		if corrected := time.Duration(secs)*time.Second + responseTime.Sub(requestTime); corrected > age {
+			age = corrected
+		}
+	}
+	age += now.Sub(responseTime)
+	ttl, ok = lifetime-age, true
+	if ttl < 0 {
+		ttl = 0
+	}
+	return
+}
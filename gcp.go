@@ -0,0 +1,223 @@
+package httpr
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	googleTokenURL  = "https://oauth2.googleapis.com/token"
+	googleJWTGrant  = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	googleTokenLife = time.Hour
+)
+
+var ErrInvalidPrivateKey = errors.New("httpr: invalid RSA private key")
+
+type GoogleServiceAccount struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+
+	key *rsa.PrivateKey
+}
+
+func ParseGoogleServiceAccount(data []byte) (a *GoogleServiceAccount, err error) {
+	a = &GoogleServiceAccount{}
+	if err = json.Unmarshal(data, a); err != nil {
+		return nil, err
+	}
+	if a.key, err = parseRSAKey(a.PrivateKey); err != nil {
+		return nil, err
+	}
+	if a.TokenURI == "" {
+		a.TokenURI = googleTokenURL
+	}
+	return
+}
+
+func LoadGoogleServiceAccount(path string) (*GoogleServiceAccount, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGoogleServiceAccount(data)
+}
+
+func parseRSAKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidPrivateKey
+	}
+	return key, nil
+}
+
+func (a *GoogleServiceAccount) jwt(claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": a.PrivateKeyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+type GoogleJWTSigner struct {
+	Account  *GoogleServiceAccount
+	Audience string
+	Lifetime time.Duration
+	Now      func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]*Token
+}
+
+func (g *GoogleJWTSigner) Sign(r *http.Request, _ []byte) error {
+	return g.SignUnsignedPayload(r)
+}
+
+func (g *GoogleJWTSigner) SignUnsignedPayload(r *http.Request) error {
+	aud := g.Audience
+	if aud == "" {
+		aud = r.URL.Scheme + "://" + r.URL.Host + "/"
+	}
+	t, err := g.token(aud)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", t.authorization())
+	return nil
+}
+
+func (g *GoogleJWTSigner) token(aud string) (t *Token, err error) {
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+	lifetime := g.Lifetime
+	if lifetime <= 0 {
+		lifetime = googleTokenLife
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if t = g.tokens[aud]; t != nil && now().Add(time.Minute).Before(t.Expiry) {
+		return
+	}
+	iat := now()
+	jwt, err := g.Account.jwt(map[string]interface{}{
+		"iss": g.Account.ClientEmail,
+		"sub": g.Account.ClientEmail,
+		"aud": aud,
+		"iat": iat.Unix(),
+		"exp": iat.Add(lifetime).Unix(),
+	})
+	if err != nil {
+		return
+	}
+	if g.tokens == nil {
+		g.tokens = map[string]*Token{}
+	}
+	t = &Token{AccessToken: jwt, TokenType: "Bearer", Expiry: iat.Add(lifetime)}
+	g.tokens[aud] = t
+	return
+}
+
+type googleTokenSource struct {
+	mu      sync.Mutex
+	account *GoogleServiceAccount
+	scopes  []string
+	token   *Token
+}
+
+func (a *GoogleServiceAccount) TokenSource(scopes ...string) TokenSource {
+	return &googleTokenSource{account: a, scopes: scopes}
+}
+
+func (s *googleTokenSource) Token() (*Token, error) {
+	return s.TokenContext(context.Background())
+}
+
+func (s *googleTokenSource) TokenContext(ctx context.Context) (t *Token, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() && time.Now().Add(time.Minute).Before(s.token.Expiry) {
+		return s.token, nil
+	}
+	iat := time.Now()
+	assertion, err := s.account.jwt(map[string]interface{}{
+		"iss":   s.account.ClientEmail,
+		"scope": strings.Join(s.scopes, " "),
+		"aud":   s.account.TokenURI,
+		"iat":   iat.Unix(),
+		"exp":   iat.Add(googleTokenLife).Unix(),
+	})
+	if err != nil {
+		return
+	}
+	form := url.Values{
+		"grant_type": {googleJWTGrant},
+		"assertion":  {assertion},
+	}
+	if t, err = exchangeToken(ctx, s.account.TokenURI, form); err != nil {
+		return
+	}
+	s.token = t
+	return
+}
+
+type tokenSigner struct {
+	ts TokenSource
+}
+
+func TokenSigner(ts TokenSource) Signer {
+	return tokenSigner{ts: ts}
+}
+
+func (s tokenSigner) Sign(r *http.Request, _ []byte) error {
+	return s.SignUnsignedPayload(r)
+}
+
+func (s tokenSigner) SignUnsignedPayload(r *http.Request) error {
+	t, err := tokenContext(r.Context(), s.ts)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", t.authorization())
+	return nil
+}
@@ -2,23 +2,44 @@ package httpr
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 )
 
+const (
+	GroupIDHeader = "X-Group-Id"
+	GroupIDTag    = "group_id"
+)
+
 type Group struct {
+	id       string
 	requests []*Request
 	sync     chan *ResponseWrapper
 	async    chan *ResponseWrapper
 	next     *context.CancelFunc
 	stop     *context.CancelFunc
+
+	mu     sync.Mutex
+	errors map[int]error
 }
 
 func NewGroup(req ...*Request) *Group {
 	return &Group{
+		id:       randomString(12),
 		requests: req,
 	}
 }
 
+func (g *Group) ID() string {
+	return g.id
+}
+
+func (g *Group) WithID(id string) *Group {
+	g.id = id
+	return g
+}
+
 func (g *Group) Continue() {
 	if g.next != nil {
 		(*g.next)()
@@ -32,29 +53,59 @@ func (g *Group) Stop() {
 }
 
 type ResponseWrapper struct {
+	Request  *Request
 	Response *Response
 	Err      error
 }
 
+func (g *Group) bind(ctx context.Context) {
+	for _, req := range g.requests {
+		if req.ctx == nil {
+			req.ctx = ctx
+		}
+		if g.id == "" {
+			continue
+		}
+		req.Tag(GroupIDTag, g.id)
+		if req.header.Get(GroupIDHeader) == "" {
+			req.Header(GroupIDHeader, g.id)
+		}
+	}
+}
+
 func (g *Group) Sync() <-chan *ResponseWrapper {
+	return g.SyncContext(context.Background())
+}
+
+func (g *Group) SyncContext(ctx context.Context) <-chan *ResponseWrapper {
 	if g.sync != nil {
 		return g.sync
 	}
+	g.bind(ctx)
 	g.sync = make(chan *ResponseWrapper)
 	go func() {
 		defer func() {
 			close(g.sync)
 			g.sync = nil
 		}()
-		for _, req := range g.requests {
+		for i, req := range g.requests {
+			if ctx.Err() != nil {
+				return
+			}
 			rsp, err := req.Response()
-			next, nextFunc := context.WithCancel(context.Background())
+			g.record(i, req, err)
+			next, nextFunc := context.WithCancel(ctx)
 			g.next = &nextFunc
-			stop, stopFunc := context.WithCancel(context.Background())
+			stop, stopFunc := context.WithCancel(ctx)
 			g.stop = &stopFunc
-			g.sync <- &ResponseWrapper{
+			select {
+			case g.sync <- &ResponseWrapper{
+				Request:  req,
 				Response: rsp,
 				Err:      err,
+			}:
+			case <-ctx.Done():
+				return
 			}
 			select {
 			case <-next.Done():
@@ -67,26 +118,128 @@ func (g *Group) Sync() <-chan *ResponseWrapper {
 }
 
 func (g *Group) Async() <-chan *ResponseWrapper {
+	return g.AsyncContext(context.Background())
+}
+
+func (g *Group) AsyncContext(ctx context.Context) <-chan *ResponseWrapper {
 	if g.async != nil {
 		return g.async
 	}
-	g.async = make(chan *ResponseWrapper, len(g.requests))
+	g.bind(ctx)
+	async := make(chan *ResponseWrapper, len(g.requests))
+	g.async = async
 	go func() {
 		var wg sync.WaitGroup
-		for _, req := range g.requests {
+		for i, req := range g.requests {
+			i, req := i, req
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
 				rsp, err := req.Response()
-				g.async <- &ResponseWrapper{
+				g.record(i, req, err)
+				async <- &ResponseWrapper{
+					Request:  req,
 					Response: rsp,
 					Err:      err,
 				}
 			}()
 		}
 		wg.Wait()
-		close(g.async)
-		g.async = nil
+		close(async)
+	}()
+	return async
+}
+
+func (g *Group) AsyncN(concurrency int) <-chan *ResponseWrapper {
+	return g.AsyncNContext(context.Background(), concurrency)
+}
+
+func (g *Group) AsyncNContext(ctx context.Context, concurrency int) <-chan *ResponseWrapper {
+	if g.async != nil {
+		return g.async
+	}
+	if concurrency <= 0 || concurrency > len(g.requests) {
+		concurrency = len(g.requests)
+	}
+	g.bind(ctx)
+	async := make(chan *ResponseWrapper, len(g.requests))
+	g.async = async
+	slots := make([]chan *ResponseWrapper, len(g.requests))
+	for i := range slots {
+		slots[i] = make(chan *ResponseWrapper, 1)
+	}
+	jobs := make(chan int)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			for i := range jobs {
+				req := g.requests[i]
+				result := &ResponseWrapper{Request: req}
+				if result.Err = ctx.Err(); result.Err == nil {
+					result.Response, result.Err = req.Response()
+				}
+				g.record(i, req, result.Err)
+				slots[i] <- result
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range g.requests {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				for ; i < len(g.requests); i++ {
+					g.record(i, g.requests[i], ctx.Err())
+					slots[i] <- &ResponseWrapper{Request: g.requests[i], Err: ctx.Err()}
+				}
+				return
+			}
+		}
+	}()
+	go func() {
+		for _, slot := range slots {
+			async <- <-slot
+		}
+		close(async)
 	}()
-	return g.async
+	return async
+}
+
+type MemberError struct {
+	Index   int
+	Request *Request
+	Err     error
+}
+
+func (e *MemberError) Error() string {
+	return fmt.Sprintf("httpr: group request %d (%s): %v", e.Index, e.Request, e.Err)
+}
+
+func (e *MemberError) Unwrap() error {
+	return e.Err
+}
+
+func (g *Group) record(i int, req *Request, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.errors == nil {
+		g.errors = map[int]error{}
+	}
+	if err == nil {
+		delete(g.errors, i)
+		return
+	}
+	g.errors[i] = &MemberError{Index: i, Request: req, Err: err}
+}
+
+func (g *Group) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var errs []error
+	for i := range g.requests {
+		if err, ok := g.errors[i]; ok {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
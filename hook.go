@@ -1,5 +1,126 @@
 package httpr
 
+import (
+	"context"
+	"errors"
+)
+
 func DumpHook(req *Request, rsp *Response) (stop bool) {
 	return
 }
+
+func (s *Service) Before(hooks ...BeforeFunc) *Service {
+	s.before = append(s.before, hooks...)
+	return s
+}
+
+func (req *Request) Before(hooks ...BeforeFunc) *Request {
+	req.before = append(req.before, hooks...)
+	return req
+}
+
+func (req *Request) runBefore() (err error) {
+	var hooks []BeforeFunc
+	if req.service != nil {
+		hooks = append(hooks, req.service.before...)
+	}
+	hooks = append(hooks, req.before...)
+	req.before = nil
+	for _, hook := range hooks {
+		if err = hook(req); err != nil {
+			req.fail(err)
+			return
+		}
+	}
+	return
+}
+
+var ErrAborted = errors.New("httpr: request aborted by hook")
+
+const maxHookRetries = 3
+
+type actionKind int
+
+const (
+	actionContinue actionKind = iota
+	actionStop
+	actionRetry
+	actionAbort
+)
+
+type Action struct {
+	kind actionKind
+	err  error
+}
+
+var (
+	Continue = Action{kind: actionContinue}
+	Stop     = Action{kind: actionStop}
+	Retry    = Action{kind: actionRetry}
+)
+
+func Abort(err error) Action {
+	if err == nil {
+		err = ErrAborted
+	}
+	return Action{kind: actionAbort, err: err}
+}
+
+type AfterHook func(r *Request, rsp *Response, err error) Action
+
+func (s *Service) After(hooks ...AfterHook) *Service {
+	s.after = append(s.after, hooks...)
+	return s
+}
+
+func (req *Request) After(hooks ...AfterHook) *Request {
+	req.after = append(req.after, hooks...)
+	return req
+}
+
+func (req *Request) runAfter(rsp *Response, err error) (action Action) {
+	var hooks []AfterHook
+	if req.service != nil {
+		hooks = append(hooks, req.service.after...)
+	}
+	hooks = append(hooks, req.after...)
+	for _, hook := range hooks {
+		if action = hook(req, rsp, err); action.kind != actionContinue {
+			return
+		}
+	}
+	return Continue
+}
+
+func (req *Request) afterAction(rsp *Response, err error) (*Response, error) {
+	action := req.runAfter(rsp, err)
+	switch action.kind {
+	case actionAbort:
+		return rsp, action.err
+	case actionRetry:
+		if req.hookRetries >= maxHookRetries {
+			return rsp, err
+		}
+		discard(rsp)
+		c := req.Clone()
+		c.hookRetries = req.hookRetries + 1
+		return c.Response()
+	}
+	return rsp, err
+}
+
+func (req *Request) Ctx() context.Context {
+	return req.context()
+}
+
+func BeforeContext(fn func(ctx context.Context, r *Request) error) BeforeFunc {
+	return func(r *Request) error {
+		return fn(r.context(), r)
+	}
+}
+
+func AfterContext(fn func(ctx context.Context, r *Request, rsp *Response, err error) Action) AfterHook {
+	return func(r *Request, rsp *Response, err error) Action {
+		return fn(r.context(), r, rsp, err)
+	}
+}
@@ -0,0 +1,241 @@
+package httprhtml
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/heramerom/httpr"
+	"golang.org/x/net/html"
+)
+
+type Document struct {
+	Root *html.Node
+}
+
+func ToHTML(rsp *httpr.Response) (doc *Document, err error) {
+	body, err := rsp.Bytes()
+	if err != nil {
+		return
+	}
+	root, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	doc = &Document{Root: root}
+	return
+}
+
+func (d *Document) Select(selector string) (nodes []*html.Node, err error) {
+	groups, err := parseSelector(selector)
+	if err != nil {
+		return
+	}
+	walk(d.Root, func(n *html.Node) {
+		for _, g := range groups {
+			if g.match(n) {
+				nodes = append(nodes, n)
+				return
+			}
+		}
+	})
+	return
+}
+
+func (d *Document) SelectText(selector string) (texts []string, err error) {
+	nodes, err := d.Select(selector)
+	if err != nil {
+		return
+	}
+	for _, n := range nodes {
+		texts = append(texts, strings.TrimSpace(Text(n)))
+	}
+	return
+}
+
+func (d *Document) Title() string {
+	texts, _ := d.SelectText("title")
+	if len(texts) == 0 {
+		return ""
+	}
+	return texts[0]
+}
+
+func Text(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(c *html.Node) {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	})
+	return b.String()
+}
+
+func Attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func walk(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+type attrMatcher struct {
+	key   string
+	value string
+	exact bool
+}
+
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrMatcher
+}
+
+func (c *compound) match(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if c.tag != "" && c.tag != "*" && c.tag != n.Data {
+		return false
+	}
+	if c.id != "" {
+		if id, _ := Attr(n, "id"); id != c.id {
+			return false
+		}
+	}
+	if len(c.classes) > 0 {
+		class, _ := Attr(n, "class")
+		fields := strings.Fields(class)
+		for _, want := range c.classes {
+			found := false
+			for _, f := range fields {
+				if f == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	for _, a := range c.attrs {
+		v, ok := Attr(n, a.key)
+		if !ok || a.exact && v != a.value {
+			return false
+		}
+	}
+	return true
+}
+
+type step struct {
+	compound
+	child bool
+}
+
+type selector []step
+
+func (s selector) match(n *html.Node) bool {
+	return s.matchAt(len(s)-1, n)
+}
+
+func (s selector) matchAt(i int, n *html.Node) bool {
+	if !s[i].compound.match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if s[i].child {
+		return n.Parent != nil && s.matchAt(i-1, n.Parent)
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if s.matchAt(i-1, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseSelector(input string) (groups []selector, err error) {
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(strings.Replace(part, ">", " > ", -1))
+		if part == "" {
+			return nil, errors.New("httprhtml: empty selector")
+		}
+		var sel selector
+		child := false
+		for _, token := range strings.Fields(part) {
+			if token == ">" {
+				child = true
+				continue
+			}
+			var c compound
+			if c, err = parseCompound(token); err != nil {
+				return
+			}
+			sel = append(sel, step{compound: c, child: child})
+			child = false
+		}
+		if len(sel) == 0 || child {
+			return nil, errors.New("httprhtml: invalid selector " + part)
+		}
+		groups = append(groups, sel)
+	}
+	return
+}
+
+func parseCompound(token string) (c compound, err error) {
+	i := strings.IndexAny(token, "#.[")
+	if i < 0 {
+		c.tag = strings.ToLower(token)
+		return
+	}
+	c.tag = strings.ToLower(token[:i])
+	rest := token[i:]
+	for rest != "" {
+		switch rest[0] {
+		case '#', '.':
+			end := strings.IndexAny(rest[1:], "#.[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return c, errors.New("httprhtml: invalid selector " + token)
+			}
+			if rest[0] == '#' {
+				c.id = name
+			} else {
+				c.classes = append(c.classes, name)
+			}
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return c, errors.New("httprhtml: unterminated attribute in " + token)
+			}
+			expr := rest[1:end]
+			a := attrMatcher{key: expr}
+			if eq := strings.IndexByte(expr, '='); eq >= 0 {
+				a.key = expr[:eq]
+				a.value = strings.Trim(expr[eq+1:], `"'`)
+				a.exact = true
+			}
+			c.attrs = append(c.attrs, a)
+			rest = rest[end+1:]
+		default:
+			return c, errors.New("httprhtml: invalid selector " + token)
+		}
+	}
+	return
+}
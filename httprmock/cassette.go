@@ -0,0 +1,261 @@
+package httprmock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const RecordEnv = "HTTPR_RECORD"
+
+type Mode int
+
+const (
+	ModeAuto Mode = iota
+	ModeReplay
+	ModeRecord
+)
+
+const scrubbed = "REDACTED"
+
+var (
+	scrubHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+	scrubParams  = []string{"access_token", "id_token", "refresh_token", "token", "api_key", "apikey", "key", "password",
+		"secret", "client_secret", "signature", "sig", "X-Amz-Signature", "X-Amz-Credential", "X-Amz-Security-Token"}
+)
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Payload     `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Payload     `json:"body,omitempty"`
+}
+
+type Payload []byte
+
+func (p Payload) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(p) {
+		return json.Marshal(string(p))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(p)})
+}
+
+func (p *Payload) UnmarshalJSON(data []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(data, &s); err == nil {
+		*p = Payload(s)
+		return
+	}
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err = json.Unmarshal(data, &encoded); err != nil {
+		return
+	}
+	*p, err = base64.StdEncoding.DecodeString(encoded.Base64)
+	return
+}
+
+type Recorder struct {
+	path      string
+	recording bool
+	real      http.RoundTripper
+
+	params       map[string]bool
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+func NewRecorder(path string, mode Mode, real http.RoundTripper) (r *Recorder, err error) {
+	if real == nil {
+		real = http.DefaultTransport
+	}
+	if os.Getenv(RecordEnv) != "" {
+		mode = ModeRecord
+	}
+	r = &Recorder{path: path, real: real, params: map[string]bool{}}
+	r.ScrubParams(scrubParams...)
+	bs, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil && mode != ModeRecord:
+		if err = json.Unmarshal(bs, &r.interactions); err != nil {
+			err = fmt.Errorf("httprmock: parse cassette %s: %w", path, err)
+			return
+		}
+		r.used = make([]bool, len(r.interactions))
+	case os.IsNotExist(err) && mode == ModeReplay:
+		err = fmt.Errorf("httprmock: cassette %s not found; set %s=1 to record it", path, RecordEnv)
+		return
+	case err != nil && !os.IsNotExist(err):
+		return
+	default:
+		r.recording = true
+	}
+	err = nil
+	return
+}
+
+func (r *Recorder) ScrubParams(names ...string) *Recorder {
+	for _, name := range names {
+		r.params[strings.ToLower(name)] = true
+	}
+	return r
+}
+
+func (r *Recorder) scrubQuery(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	parts := strings.Split(raw, "&")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if name, err := url.QueryUnescape(key); err == nil && r.params[strings.ToLower(name)] {
+			parts[i] = key + "=" + scrubbed
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func (r *Recorder) scrubURL(u *url.URL) string {
+	dup := *u
+	dup.RawQuery = r.scrubQuery(u.RawQuery)
+	return dup.String()
+}
+
+func (r *Recorder) scrubBody(h http.Header, body []byte) []byte {
+	if mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type")); err != nil || mediaType != "application/x-www-form-urlencoded" {
+		return body
+	}
+	return []byte(r.scrubQuery(string(body)))
+}
+
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (rsp *http.Response, err error) {
+	var body []byte
+	if req.Body != nil {
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if !r.recording {
+		return r.replay(req, body)
+	}
+	rsp, err = r.real.RoundTrip(req)
+	if err != nil {
+		return
+	}
+	data, err := ioutil.ReadAll(rsp.Body)
+	rsp.Body.Close()
+	if err != nil {
+		return
+	}
+	rsp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    r.scrubURL(req.URL),
+			Header: scrub(req.Header),
+			Body:   r.scrubBody(req.Header, body),
+		},
+		Response: RecordedResponse{
+			StatusCode: rsp.StatusCode,
+			Header:     scrub(rsp.Header),
+			Body:       data,
+		},
+	})
+	r.mu.Unlock()
+	return
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (rsp *http.Response, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	uri, body := r.scrubURL(req.URL), r.scrubBody(req.Header, body)
+	match := -1
+	for i, in := range r.interactions {
+		if in.Request.Method != req.Method || in.Request.URL != uri || !bytes.Equal(in.Request.Body, body) {
+			continue
+		}
+		if !r.used[i] {
+			match = i
+			break
+		}
+		if match < 0 {
+			match = i
+		}
+	}
+	if match < 0 {
+		err = fmt.Errorf("httprmock: no recorded interaction for %s %s in %s", req.Method, uri, r.path)
+		return
+	}
+	r.used[match] = true
+	in := r.interactions[match].Response
+	rsp = &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.StatusCode, http.StatusText(in.StatusCode)),
+		StatusCode:    in.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        in.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(in.Body)),
+		ContentLength: int64(len(in.Body)),
+		Request:       req,
+	}
+	if rsp.Header == nil {
+		rsp.Header = http.Header{}
+	}
+	return
+}
+
+func (r *Recorder) Save() (err error) {
+	if !r.recording {
+		return
+	}
+	r.mu.Lock()
+	bs, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return
+	}
+	err = ioutil.WriteFile(r.path, append(bs, '\n'), 0644)
+	return
+}
+
+func scrub(h http.Header) http.Header {
+	h = h.Clone()
+	for _, key := range scrubHeaders {
+		h.Del(key)
+	}
+	return h
+}
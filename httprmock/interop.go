@@ -0,0 +1,25 @@
+package httprmock
+
+import (
+	"net/http"
+
+	"github.com/heramerom/httpr"
+)
+
+func Intercepted() bool {
+	_, ok := http.DefaultTransport.(*http.Transport)
+	return !ok
+}
+
+func Interop(s *httpr.Service) *httpr.Service {
+	return s.Use(func(next httpr.RoundTripFunc) httpr.RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			if !Intercepted() {
+				return next(r)
+			}
+			c := *s.HTTPClient()
+			c.Transport = http.DefaultTransport
+			return c.Do(r)
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package httprmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Mock struct {
+	mu       sync.Mutex
+	stubs    []*Stub
+	fallback http.RoundTripper
+}
+
+func New() *Mock {
+	return &Mock{}
+}
+
+func (m *Mock) On(method, path string) *Stub {
+	s := &Stub{
+		method: strings.ToUpper(method),
+		path:   path,
+		status: http.StatusOK,
+		header: http.Header{},
+	}
+	m.mu.Lock()
+	m.stubs = append(m.stubs, s)
+	m.mu.Unlock()
+	return s
+}
+
+func (m *Mock) Fallback(rt http.RoundTripper) *Mock {
+	m.fallback = rt
+	return m
+}
+
+func (m *Mock) Pending() (stubs []*Stub) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.stubs {
+		if s.calls == 0 || (s.times > 0 && s.calls < s.times) {
+			stubs = append(stubs, s)
+		}
+	}
+	return
+}
+
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	m.stubs = nil
+	m.mu.Unlock()
+}
+
+func (m *Mock) RoundTrip(r *http.Request) (*http.Response, error) {
+	var body []byte
+	if r.Body != nil {
+		body, _ = ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	m.mu.Lock()
+	var stub *Stub
+	for _, s := range m.stubs {
+		if s.exhausted() || !s.matches(r, body) {
+			continue
+		}
+		s.calls++
+		stub = s
+		break
+	}
+	m.mu.Unlock()
+	if stub == nil {
+		if m.fallback != nil {
+			return m.fallback.RoundTrip(r)
+		}
+		return nil, fmt.Errorf("httprmock: no stub for %s %s", r.Method, r.URL)
+	}
+	return stub.respond(r)
+}
+
+type Stub struct {
+	method string
+	path   string
+	query  url.Values
+	match  http.Header
+	bodyFn func([]byte) bool
+	times  int
+	calls  int
+
+	status int
+	header http.Header
+	body   []byte
+	delay  time.Duration
+	err    error
+}
+
+func (s *Stub) String() string {
+	return s.method + " " + s.path
+}
+
+func (s *Stub) Query(key, value string) *Stub {
+	if s.query == nil {
+		s.query = url.Values{}
+	}
+	s.query.Add(key, value)
+	return s
+}
+
+func (s *Stub) MatchHeader(key, value string) *Stub {
+	if s.match == nil {
+		s.match = http.Header{}
+	}
+	s.match.Add(key, value)
+	return s
+}
+
+func (s *Stub) MatchBody(fn func(body []byte) bool) *Stub {
+	s.bodyFn = fn
+	return s
+}
+
+func (s *Stub) Times(n int) *Stub {
+	s.times = n
+	return s
+}
+
+func (s *Stub) Once() *Stub {
+	return s.Times(1)
+}
+
+func (s *Stub) Reply(status int) *Stub {
+	s.status = status
+	return s
+}
+
+func (s *Stub) Header(key, value string) *Stub {
+	s.header.Add(key, value)
+	return s
+}
+
+func (s *Stub) Body(body string) *Stub {
+	s.body = []byte(body)
+	return s
+}
+
+func (s *Stub) JSON(v interface{}) *Stub {
+	switch b := v.(type) {
+	case string:
+		s.body = []byte(b)
+	case []byte:
+		s.body = b
+	default:
+		bs, err := json.Marshal(v)
+		if err != nil {
+			s.err = err
+			return s
+		}
+		s.body = bs
+	}
+	if s.header.Get("Content-Type") == "" {
+		s.header.Set("Content-Type", "application/json")
+	}
+	return s
+}
+
+func (s *Stub) Delay(d time.Duration) *Stub {
+	s.delay = d
+	return s
+}
+
+func (s *Stub) Error(err error) *Stub {
+	s.err = err
+	return s
+}
+
+func (s *Stub) exhausted() bool {
+	return s.times > 0 && s.calls >= s.times
+}
+
+func (s *Stub) matches(r *http.Request, body []byte) bool {
+	if s.method != "" && s.method != r.Method {
+		return false
+	}
+	if strings.Contains(s.path, "://") {
+		u := *r.URL
+		u.RawQuery, u.Fragment = "", ""
+		if u.String() != s.path {
+			return false
+		}
+	} else if s.path != "" && s.path != r.URL.Path {
+		return false
+	}
+	query := r.URL.Query()
+	for key, values := range s.query {
+		for _, value := range values {
+			if !contains(query[key], value) {
+				return false
+			}
+		}
+	}
+	for key, values := range s.match {
+		for _, value := range values {
+			if !contains(r.Header.Values(key), value) {
+				return false
+			}
+		}
+	}
+	if s.bodyFn != nil && !s.bodyFn(body) {
+		return false
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Stub) respond(r *http.Request) (*http.Response, error) {
+	if s.delay > 0 {
+		t := time.NewTimer(s.delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", s.status, http.StatusText(s.status)),
+		StatusCode:    s.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        s.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(s.body)),
+		ContentLength: int64(len(s.body)),
+		Request:       r,
+	}, nil
+}
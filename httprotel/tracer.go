@@ -0,0 +1,77 @@
+package httprotel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/heramerom/httpr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func New(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &Tracer{
+		tracer:     tp.Tracer("github.com/heramerom/httpr"),
+		propagator: propagation.TraceContext{},
+	}
+}
+
+func (t *Tracer) Propagator(p propagation.TextMapPropagator) *Tracer {
+	t.propagator = p
+	return t
+}
+
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, httpr.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, &Span{span: span}
+}
+
+func (t *Tracer) Inject(ctx context.Context, header http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+type Span struct {
+	span trace.Span
+}
+
+func (s *Span) SetAttributes(attrs map[string]interface{}) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for key, value := range attrs {
+		switch v := value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(key, v))
+		default:
+			kvs = append(kvs, attribute.String(key, fmt.Sprint(v)))
+		}
+	}
+	s.span.SetAttributes(kvs...)
+}
+
+func (s *Span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *Span) End() {
+	s.span.End()
+}
@@ -0,0 +1,72 @@
+package httprprom
+
+import (
+	"strconv"
+
+	"github.com/heramerom/httpr"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var labels = []string{"service", "method", "path"}
+
+type Collector struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	outcomes *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+}
+
+func NewCollector(namespace string) *Collector {
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "httpr",
+			Name:      "requests_total",
+			Help:      "Outbound requests by service, method, path template and status code.",
+		}, append(labels, "code")),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "httpr",
+			Name:      "errors_total",
+			Help:      "Outbound requests that failed without a response.",
+		}, labels),
+		outcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "httpr",
+			Name:      "outcomes_total",
+			Help:      "Outbound calls by outcome: first_try, after_retry or failed.",
+		}, append(labels, "outcome")),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "httpr",
+			Name:      "request_duration_seconds",
+			Help:      "Outbound request latency.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+	}
+}
+
+func (c *Collector) Observe(m httpr.RequestMetric) {
+	values := []string{m.Service, m.Method, m.Path}
+	c.latency.WithLabelValues(values...).Observe(m.Latency.Seconds())
+	c.outcomes.WithLabelValues(append(values, m.Outcome())...).Inc()
+	if m.Err != nil {
+		c.errors.WithLabelValues(values...).Inc()
+		return
+	}
+	c.requests.WithLabelValues(append(values, strconv.Itoa(m.Status))...).Inc()
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.errors.Describe(ch)
+	c.outcomes.Describe(ch)
+	c.latency.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.errors.Collect(ch)
+	c.outcomes.Collect(ch)
+	c.latency.Collect(ch)
+}
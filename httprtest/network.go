@@ -0,0 +1,114 @@
+package httprtest
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Network struct {
+	Latency    time.Duration
+	Jitter     time.Duration
+	Bandwidth  int64
+	ChunkSize  int
+	StallEvery int64
+	StallFor   time.Duration
+	Seed       int64
+	Transport  http.RoundTripper
+
+	once sync.Once
+	mu   sync.Mutex
+	rnd  *rand.Rand
+}
+
+func (n *Network) jitter() time.Duration {
+	if n.Jitter <= 0 {
+		return 0
+	}
+	n.once.Do(func() {
+		seed := n.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		n.rnd = rand.New(rand.NewSource(seed))
+	})
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return time.Duration(n.rnd.Int63n(int64(2*n.Jitter))) - n.Jitter
+}
+
+func (n *Network) delay() time.Duration {
+	d := n.Latency + n.jitter()
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (n *Network) RoundTrip(r *http.Request) (*http.Response, error) {
+	rt := n.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if err := wait(r.Context(), n.delay()); err != nil {
+		return nil, err
+	}
+	rsp, err := rt.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	rsp.Body = &slowBody{body: rsp.Body, ctx: r.Context(), network: n}
+	return rsp, nil
+}
+
+type slowBody struct {
+	body    io.ReadCloser
+	ctx     context.Context
+	network *Network
+	read    int64
+	stalled int64
+}
+
+func (b *slowBody) Read(p []byte) (n int, err error) {
+	net := b.network
+	if size := net.ChunkSize; size > 0 && len(p) > size {
+		p = p[:size]
+	}
+	if net.StallEvery > 0 && net.StallFor > 0 && b.read-b.stalled >= net.StallEvery {
+		b.stalled = b.read
+		if err = wait(b.ctx, net.StallFor); err != nil {
+			return
+		}
+	}
+	start := time.Now()
+	n, err = b.body.Read(p)
+	b.read += int64(n)
+	if net.Bandwidth > 0 && n > 0 {
+		d := time.Duration(int64(n)*int64(time.Second)/net.Bandwidth) - time.Since(start)
+		if werr := wait(b.ctx, d); werr != nil {
+			return n, werr
+		}
+	}
+	return
+}
+
+func (b *slowBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,114 @@
+package httprtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/heramerom/httpr"
+)
+
+const UpdateEnv = "HTTPR_UPDATE_SNAPSHOTS"
+
+type Rule func(body []byte) []byte
+
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	uuidPattern      = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+)
+
+func Regexp(pattern, replacement string) Rule {
+	re := regexp.MustCompile(pattern)
+	return func(body []byte) []byte {
+		return re.ReplaceAll(body, []byte(replacement))
+	}
+}
+
+func Timestamps() Rule {
+	return func(body []byte) []byte {
+		return timestampPattern.ReplaceAll(body, []byte("<timestamp>"))
+	}
+}
+
+func UUIDs() Rule {
+	return func(body []byte) []byte {
+		return uuidPattern.ReplaceAll(body, []byte("<uuid>"))
+	}
+}
+
+func Fields(placeholder string, keys ...string) Rule {
+	set := map[string]bool{}
+	for _, key := range keys {
+		set[key] = true
+	}
+	return func(body []byte) []byte {
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err != nil {
+			return body
+		}
+		bs, err := json.Marshal(maskFields(v, set, placeholder))
+		if err != nil {
+			return body
+		}
+		return bs
+	}
+}
+
+func maskFields(v interface{}, keys map[string]bool, placeholder string) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for key, value := range vv {
+			if keys[key] {
+				vv[key] = placeholder
+				continue
+			}
+			vv[key] = maskFields(value, keys, placeholder)
+		}
+	case []interface{}:
+		for i, value := range vv {
+			vv[i] = maskFields(value, keys, placeholder)
+		}
+	}
+	return v
+}
+
+func Snapshot(t testing.TB, rsp *httpr.Response, path string, rules ...Rule) {
+	t.Helper()
+	body, err := rsp.Bytes()
+	if err != nil {
+		t.Fatalf("httprtest: read body: %v", err)
+	}
+	for _, rule := range rules {
+		body = rule(body)
+	}
+	body = normalize(body)
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("httprtest: %v", err)
+		}
+		if err := ioutil.WriteFile(path, body, 0644); err != nil {
+			t.Fatalf("httprtest: %v", err)
+		}
+		return
+	}
+	golden, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("httprtest: read snapshot %s: %v (set %s=1 to create it)", path, err, UpdateEnv)
+	}
+	if !bytes.Equal(golden, body) {
+		t.Errorf("httprtest: response does not match snapshot %s\n--- want\n%s\n--- got\n%s", path, golden, body)
+	}
+}
+
+func normalize(body []byte) []byte {
+	var out bytes.Buffer
+	if json.Valid(body) && json.Indent(&out, body, "", "  ") == nil {
+		out.WriteByte('\n')
+		return out.Bytes()
+	}
+	return body
+}
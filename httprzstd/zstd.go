@@ -0,0 +1,63 @@
+package httprzstd
+
+import (
+	"bytes"
+	"hash/crc32"
+	"io"
+
+	"github.com/heramerom/httpr"
+	"github.com/klauspost/compress/zstd"
+)
+
+const Encoding = "zstd"
+
+var dictMagic = []byte{0x37, 0xa4, 0x30, 0xec}
+
+func rawID(dict []byte) uint32 {
+	id := crc32.ChecksumIEEE(dict)
+	if id == 0 {
+		id = 1
+	}
+	return id
+}
+
+func Decoder(dicts ...[]byte) httpr.ContentDecoder {
+	var opts []zstd.DOption
+	for _, dict := range dicts {
+		if bytes.HasPrefix(dict, dictMagic) {
+			opts = append(opts, zstd.WithDecoderDicts(dict))
+		} else {
+			opts = append(opts, zstd.WithDecoderDictRaw(rawID(dict), dict))
+		}
+	}
+	return func(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+}
+
+func Encoder(dict []byte, level zstd.EncoderLevel) httpr.ContentEncoder {
+	opts := []zstd.EOption{zstd.WithEncoderLevel(level)}
+	switch {
+	case len(dict) == 0:
+	case bytes.HasPrefix(dict, dictMagic):
+		opts = append(opts, zstd.WithEncoderDict(dict))
+	default:
+		opts = append(opts, zstd.WithEncoderDictRaw(rawID(dict), dict))
+	}
+	return func(w io.Writer) (io.WriteCloser, error) {
+		return zstd.NewWriter(w, opts...)
+	}
+}
+
+func Register() {
+	httpr.RegisterContentEncoding(Encoding, Decoder())
+}
+
+func Dictionary(s *httpr.Service, dict []byte) *httpr.Service {
+	return s.ContentEncoding(Encoding, Decoder(dict)).
+		CompressWith(Encoding, Encoder(dict, zstd.SpeedDefault))
+}
@@ -0,0 +1,98 @@
+package httpr
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+)
+
+func (rsp *Response) JsonStream(fn func(raw json.RawMessage) error) (err error) {
+	body, err := rsp.Reader()
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	br := bufio.NewReader(body)
+	array, err := peekArray(br)
+	if err != nil {
+		if err == io.EOF {
+			err = nil
+		}
+		return
+	}
+	dec := json.NewDecoder(br)
+	if array {
+		if _, err = dec.Token(); err != nil {
+			return
+		}
+	}
+	for !array || dec.More() {
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			if err == io.EOF && !array {
+				err = nil
+			}
+			return
+		}
+		if err = fn(raw); err != nil {
+			return
+		}
+	}
+	_, err = dec.Token()
+	return
+}
+
+func peekArray(br *bufio.Reader) (array bool, err error) {
+	for {
+		var b byte
+		if b, err = br.ReadByte(); err != nil {
+			return
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		err = br.UnreadByte()
+		array = b == '['
+		return
+	}
+}
+
+func JsonStreamOf[T any](rsp *Response, fn func(v T) error) error {
+	return rsp.JsonStream(func(raw json.RawMessage) error {
+		var v T
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		return fn(v)
+	})
+}
+
+func StreamJSON[T any](ctx context.Context, rsp *Response, bufSize int) (<-chan T, <-chan error) {
+	items := make(chan T, bufSize)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(items)
+		stop := context.AfterFunc(ctx, func() {
+			rsp.Close()
+		})
+		defer stop()
+		err := JsonStreamOf(rsp, func(v T) error {
+			select {
+			case items <- v:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return items, errs
+}
@@ -0,0 +1,92 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var ErrResponseTooLarge = errors.New("httpr: response body too large")
+
+type ResponseTooLargeError struct {
+	Limit         int64
+	ContentLength int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	if e.ContentLength > 0 {
+		return fmt.Sprintf("%v: content length %d exceeds limit of %d bytes", ErrResponseTooLarge, e.ContentLength, e.Limit)
+	}
+	return fmt.Sprintf("%v: exceeds limit of %d bytes", ErrResponseTooLarge, e.Limit)
+}
+
+func (e *ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+func (s *Service) MaxResponseBytes(n int64) *Service {
+	s.maxResponseBytes = n
+	return s
+}
+
+func (req *Request) MaxResponseBytes(n int64) *Request {
+	req.maxResponseBytes = n
+	return req
+}
+
+func (req *Request) responseLimit() int64 {
+	if req.maxResponseBytes != 0 || req.service == nil {
+		return req.maxResponseBytes
+	}
+	return req.service.maxResponseBytes
+}
+
+type responseLimitKey struct{}
+
+func (req *Request) limitContext(ctx context.Context) context.Context {
+	if limit := req.responseLimit(); limit > 0 {
+		return context.WithValue(ctx, responseLimitKey{}, limit)
+	}
+	return ctx
+}
+
+func responseLimitOf(r *http.Request) int64 {
+	limit, _ := r.Context().Value(responseLimitKey{}).(int64)
+	return limit
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (n int, err error) {
+	if b.remaining < 0 {
+		return 0, &ResponseTooLargeError{Limit: b.limit}
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err = b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		n += int(b.remaining)
+		err = &ResponseTooLargeError{Limit: b.limit}
+	}
+	return
+}
+
+func (req *Request) limitBody(resp *http.Response) error {
+	limit := req.responseLimit()
+	if limit <= 0 {
+		return nil
+	}
+	if resp.ContentLength > limit {
+		return &ResponseTooLargeError{Limit: limit, ContentLength: resp.ContentLength}
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, limit: limit, remaining: limit}
+	return nil
+}
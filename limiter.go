@@ -0,0 +1,86 @@
+package httpr
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type hostSlot struct {
+	sem  chan struct{}
+	next time.Time
+}
+
+type HostLimiter struct {
+	concurrency int
+	interval    time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+}
+
+func NewHostLimiter(concurrency int, interval time.Duration) *HostLimiter {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &HostLimiter{
+		concurrency: concurrency,
+		interval:    interval,
+		hosts:       map[string]*hostSlot{},
+	}
+}
+
+func (l *HostLimiter) slot(host string) *hostSlot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.hosts[host]
+	if !ok {
+		s = &hostSlot{sem: make(chan struct{}, l.concurrency)}
+		l.hosts[host] = s
+	}
+	return s
+}
+
+func (l *HostLimiter) Acquire(ctx context.Context, host string) (release func(), err error) {
+	s := l.slot(host)
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if l.interval > 0 {
+		l.mu.Lock()
+		now := time.Now()
+		wait := s.next.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		s.next = now.Add(wait + l.interval)
+		l.mu.Unlock()
+		if err = sleep(ctx, wait); err != nil {
+			<-s.sem
+			return nil, err
+		}
+	}
+	return func() { <-s.sem }, nil
+}
+
+func (s *Service) HostLimiter(l *HostLimiter) *Service {
+	s.limiter = l
+	return s
+}
+
+func (req *Request) Limiter(l *HostLimiter) *Request {
+	req.limiter = l
+	return req
+}
+
+func (req *Request) hostLimiter() *HostLimiter {
+	if req.limiter != nil {
+		return req.limiter
+	}
+	if req.service != nil {
+		return req.service.limiter
+	}
+	return nil
+}
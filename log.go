@@ -1,21 +1,123 @@
 package httpr
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
 
 type logger interface {
 	Infof(format string, args ...interface{})
 	Errorf(format string, args ...interface{})
 }
 
-type emptyLogger struct{}
+type Level int
 
-func (emptyLogger) Errorf(format string, args ...interface{}) {
-	fmt.Printf("[httpr]: "+format, args...)
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+	LevelOff   Level = 1 << 30
+)
+
+func (l Level) String() string {
+	switch {
+	case l >= LevelOff:
+		return "OFF"
+	case l >= LevelError:
+		return "ERROR"
+	case l >= LevelWarn:
+		return "WARN"
+	case l >= LevelInfo:
+		return "INFO"
+	}
+	return "DEBUG"
+}
+
+type StructuredLogger interface {
+	Log(level Level, msg string, keyvals ...interface{})
+}
+
+type LoggerFunc func(level Level, msg string, keyvals ...interface{})
+
+func (f LoggerFunc) Log(level Level, msg string, keyvals ...interface{}) {
+	f(level, msg, keyvals...)
 }
 
-func (emptyLogger) Infof(format string, args ...interface{}) {}
+type KeyValueLogger struct {
+	mu  sync.Mutex
+	w   io.Writer
+	min Level
+}
 
-var defaultLogger logger = emptyLogger{}
+func NewKeyValueLogger(w io.Writer, min Level) *KeyValueLogger {
+	return &KeyValueLogger{w: w, min: min}
+}
+
+func (l *KeyValueLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	if level < l.min || level >= LevelOff {
+		return
+	}
+	var b strings.Builder
+	b.WriteString("time=")
+	b.WriteString(time.Now().Format(time.RFC3339))
+	b.WriteString(" level=")
+	b.WriteString(level.String())
+	b.WriteString(" msg=")
+	b.WriteString(logValue(msg))
+	for i := 0; i < len(keyvals); i += 2 {
+		b.WriteByte(' ')
+		b.WriteString(fmt.Sprint(keyvals[i]))
+		b.WriteByte('=')
+		if i+1 < len(keyvals) {
+			b.WriteString(logValue(keyvals[i+1]))
+		} else {
+			b.WriteString(`""`)
+		}
+	}
+	b.WriteByte('\n')
+	l.mu.Lock()
+	io.WriteString(l.w, b.String())
+	l.mu.Unlock()
+}
+
+func logValue(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case error:
+		s = v.Error()
+	case time.Duration:
+		s = v.String()
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+type structuredPrintf struct{}
+
+func (structuredPrintf) Errorf(format string, args ...interface{}) {
+	defaultStructured.Log(LevelError, strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (structuredPrintf) Infof(format string, args ...interface{}) {
+	defaultStructured.Log(LevelInfo, strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+var (
+	defaultStructured StructuredLogger = NewKeyValueLogger(os.Stderr, LevelInfo)
+	defaultLogger     logger           = structuredPrintf{}
+)
 
 func SetLogger(l logger) {
 	if l == nil {
@@ -23,3 +125,87 @@ func SetLogger(l logger) {
 	}
 	defaultLogger = l
 }
+
+func SetStructuredLogger(l StructuredLogger) {
+	if l == nil {
+		panic("logger cannot be nil")
+	}
+	defaultStructured = l
+}
+
+type requestLog struct {
+	logger  StructuredLogger
+	success Level
+	retried Level
+	failure Level
+}
+
+func (s *Service) LogRequests(l StructuredLogger, success, failure Level) *Service {
+	s.requestLog = &requestLog{logger: l, success: success, retried: success, failure: failure}
+	return s
+}
+
+func (s *Service) LogRetried(level Level) *Service {
+	if s.requestLog != nil {
+		s.requestLog.retried = level
+	}
+	return s
+}
+
+func (req *Request) logRequest(rsp *Response, err error) {
+	if req.service == nil || req.service.requestLog == nil {
+		return
+	}
+	rl := req.service.requestLog
+	level := rl.success
+	switch {
+	case err != nil:
+		level = rl.failure
+	case req.attempts > 1:
+		level = rl.retried
+	}
+	if level >= LevelOff {
+		return
+	}
+	l := rl.logger
+	if l == nil {
+		l = defaultStructured
+	}
+	keyvals := []interface{}{
+		"service", req.service.name,
+		"method", req.method,
+		"url", req.logURL(),
+	}
+	if rsp != nil {
+		keyvals = append(keyvals, "status", rsp.StatusCode())
+	}
+	keyvals = append(keyvals,
+		"latency", req.endAt.Sub(req.startAt),
+		"retries", retries(req.attempts),
+	)
+	if id, ok := req.tags[GroupIDTag]; ok {
+		keyvals = append(keyvals, GroupIDTag, id)
+	}
+	if err != nil {
+		keyvals = append(keyvals, "error", err)
+	}
+	l.Log(level, "http request", keyvals...)
+}
+
+func (req *Request) logURL() string {
+	if req.req != nil {
+		return req.redactor().url(req.req.URL)
+	}
+	uri, err := req.url()
+	if err != nil {
+		uri = req.host + req.uri
+	}
+	return req.redactor().rawURL(uri)
+}
+
+func retries(attempts int) int {
+	if attempts > 1 {
+		return attempts - 1
+	}
+	return 0
+}
@@ -0,0 +1,26 @@
+//go:build go1.21
+
+package httpr
+
+import (
+	"context"
+	"log/slog"
+)
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func SlogLogger(l *slog.Logger) StructuredLogger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return slogLogger{l: l}
+}
+
+func (s slogLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	if level >= LevelOff {
+		return
+	}
+	s.l.Log(context.Background(), slog.Level(level), msg, keyvals...)
+}
@@ -0,0 +1,75 @@
+package httpr
+
+import (
+	"net/url"
+	"time"
+)
+
+const (
+	OutcomeFirstTry   = "first_try"
+	OutcomeAfterRetry = "after_retry"
+	OutcomeFailed     = "failed"
+)
+
+type RequestMetric struct {
+	Service  string
+	Method   string
+	Path     string
+	Status   int
+	Err      error
+	Latency  time.Duration
+	Attempts int
+}
+
+func (m RequestMetric) Outcome() string {
+	switch {
+	case m.Err != nil || m.Status >= 500:
+		return OutcomeFailed
+	case m.Attempts > 1:
+		return OutcomeAfterRetry
+	}
+	return OutcomeFirstTry
+}
+
+type MetricsSink interface {
+	Observe(m RequestMetric)
+}
+
+func (s *Service) Name(name string) *Service {
+	s.name = name
+	return s
+}
+
+func (s *Service) Metrics(sink MetricsSink) *Service {
+	s.metrics = sink
+	return s
+}
+
+func (req *Request) pathTemplate() string {
+	u, err := url.Parse(req.uri)
+	if err != nil {
+		return req.uri
+	}
+	if u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
+func (req *Request) observe(rsp *Response, err error) {
+	if req.service == nil || req.service.metrics == nil {
+		return
+	}
+	m := RequestMetric{
+		Service:  req.service.name,
+		Method:   req.method,
+		Path:     req.pathTemplate(),
+		Err:      err,
+		Latency:  req.endAt.Sub(req.startAt),
+		Attempts: req.attempts,
+	}
+	if rsp != nil {
+		m.Status = rsp.StatusCode()
+	}
+	req.service.metrics.Observe(m)
+}
@@ -0,0 +1,37 @@
+package httpr
+
+import "net/http"
+
+type RoundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f RoundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+func (s *Service) Use(middlewares ...Middleware) *Service {
+	s.middlewares = append(s.middlewares, middlewares...)
+	return s
+}
+
+func chain(rt RoundTripFunc, middlewares []Middleware) RoundTripFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+	return rt
+}
+
+func (req *Request) roundTrip() RoundTripFunc {
+	c, err := req.client()
+	if err != nil {
+		return func(*http.Request) (*http.Response, error) {
+			return nil, err
+		}
+	}
+	rt := req.sign(c.Do)
+	if req.service != nil {
+		rt = chain(rt, req.service.middlewares)
+	}
+	return rt
+}
@@ -0,0 +1,259 @@
+package httpr
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Token struct {
+	AccessToken  string
+	TokenType    string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Before(t.Expiry))
+}
+
+type TokenSource interface {
+	Token() (*Token, error)
+}
+
+type ContextTokenSource interface {
+	TokenContext(ctx context.Context) (*Token, error)
+}
+
+func tokenContext(ctx context.Context, ts TokenSource) (*Token, error) {
+	if cts, ok := ts.(ContextTokenSource); ok {
+		return cts.TokenContext(ctx)
+	}
+	return ts.Token()
+}
+
+func (s *Service) TokenSource(ts TokenSource) *Service {
+	return s.credential("Authorization", func(ctx context.Context) (string, error) {
+		t, err := tokenContext(ctx, ts)
+		if err != nil {
+			return "", err
+		}
+		return t.authorization(), nil
+	})
+}
+
+func (t *Token) authorization() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func exchangeToken(ctx context.Context, tokenURL string, form url.Values) (t *Token, err error) {
+	rsp, err := NewRequest(http.MethodPost, tokenURL).Context(ctx).Form(form).Response()
+	if err != nil {
+		return
+	}
+	var tr tokenResponse
+	if err = rsp.ToJson(&tr); err != nil {
+		return
+	}
+	if tr.Error != "" || tr.AccessToken == "" {
+		err = fmt.Errorf("httpr: oauth2 token exchange failed: status %d: %s %s", rsp.StatusCode(), tr.Error, tr.ErrorDescription)
+		return
+	}
+	t = &Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+	}
+	if tr.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return
+}
+
+type AuthCodeConfig struct {
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	Scopes       []string
+	RedirectPort int
+	OpenBrowser  func(url string) error
+}
+
+func (c *AuthCodeConfig) form(values url.Values) url.Values {
+	values.Set("client_id", c.ClientID)
+	if c.ClientSecret != "" {
+		values.Set("client_secret", c.ClientSecret)
+	}
+	return values
+}
+
+func AuthCodeLogin(ctx context.Context, conf AuthCodeConfig) (ts TokenSource, err error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(conf.RedirectPort))
+	if err != nil {
+		return
+	}
+	defer ln.Close()
+	redirectURI := "http://" + ln.Addr().String() + "/callback"
+	state := randomString(16)
+	verifier := randomString(32)
+	challenge := sha256.Sum256([]byte(verifier))
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {conf.ClientID},
+		"redirect_uri":          {redirectURI},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if len(conf.Scopes) > 0 {
+		query.Set("scope", strings.Join(conf.Scopes, " "))
+	}
+	authURL := conf.AuthURL
+	if strings.Contains(authURL, "?") {
+		authURL += "&" + query.Encode()
+	} else {
+		authURL += "?" + query.Encode()
+	}
+
+	codes := make(chan string, 1)
+	errs := make(chan error, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		switch {
+		case q.Get("state") != state:
+			http.Error(w, "invalid state", http.StatusBadRequest)
+		case q.Get("error") != "":
+			http.Error(w, "authorization failed", http.StatusBadRequest)
+			select {
+			case errs <- fmt.Errorf("httpr: oauth2 authorization failed: %s %s", q.Get("error"), q.Get("error_description")):
+			default:
+			}
+		default:
+			fmt.Fprintln(w, "Login complete, you can close this window.")
+			select {
+			case codes <- q.Get("code"):
+			default:
+			}
+		}
+	})}
+	go server.Serve(ln)
+	defer server.Close()
+
+	open := conf.OpenBrowser
+	if open == nil {
+		open = openBrowser
+	}
+	if oerr := open(authURL); oerr != nil {
+		defaultLogger.Infof("open %s in your browser to continue\n", authURL)
+	}
+
+	var code string
+	select {
+	case code = <-codes:
+	case err = <-errs:
+		return
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	}
+	t, err := exchangeToken(ctx, conf.TokenURL, conf.form(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	}))
+	if err != nil {
+		return
+	}
+	ts = &refreshTokenSource{conf: conf, token: t}
+	return
+}
+
+func (s *Service) AuthCodeLogin(ctx context.Context, conf AuthCodeConfig) error {
+	ts, err := AuthCodeLogin(ctx, conf)
+	if err != nil {
+		return err
+	}
+	s.TokenSource(ts)
+	return nil
+}
+
+type refreshTokenSource struct {
+	mu    sync.Mutex
+	conf  AuthCodeConfig
+	token *Token
+}
+
+func (s *refreshTokenSource) Token() (*Token, error) {
+	return s.TokenContext(context.Background())
+}
+
+func (s *refreshTokenSource) TokenContext(ctx context.Context) (t *Token, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	if s.token.RefreshToken == "" {
+		return nil, errors.New("httpr: oauth2 token expired and no refresh token available")
+	}
+	t, err = exchangeToken(ctx, s.conf.TokenURL, s.conf.form(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {s.token.RefreshToken},
+	}))
+	if err != nil {
+		return
+	}
+	if t.RefreshToken == "" {
+		t.RefreshToken = s.token.RefreshToken
+	}
+	s.token = t
+	return
+}
+
+func openBrowser(u string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", u).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", u).Start()
+	}
+	return exec.Command("xdg-open", u).Start()
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,94 @@
+package httpr
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ClientCredentials struct {
+	ClientID       string
+	ClientSecret   string
+	TokenURL       string
+	Scopes         []string
+	EndpointParams url.Values
+	RefreshBefore  time.Duration
+}
+
+type clientCredentialsSource struct {
+	mu    sync.Mutex
+	conf  ClientCredentials
+	token *Token
+}
+
+func (s *clientCredentialsSource) Token() (*Token, error) {
+	return s.TokenContext(context.Background())
+}
+
+func (s *clientCredentialsSource) TokenContext(ctx context.Context) (t *Token, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() && (s.token.Expiry.IsZero() || time.Now().Add(s.conf.RefreshBefore).Before(s.token.Expiry)) {
+		return s.token, nil
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {s.conf.ClientID},
+		"client_secret": {s.conf.ClientSecret},
+	}
+	if len(s.conf.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.conf.Scopes, " "))
+	}
+	for key, values := range s.conf.EndpointParams {
+		form[key] = values
+	}
+	t, err = exchangeToken(ctx, s.conf.TokenURL, form)
+	if err != nil {
+		return
+	}
+	s.token = t
+	return
+}
+
+func (s *clientCredentialsSource) invalidate() {
+	s.mu.Lock()
+	s.token = nil
+	s.mu.Unlock()
+}
+
+func (s *Service) OAuth2(conf ClientCredentials) *Service {
+	if conf.RefreshBefore == 0 {
+		conf.RefreshBefore = time.Minute
+	}
+	ts := &clientCredentialsSource{conf: conf}
+	return s.TokenSource(ts).Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			resp, err := next(r)
+			if err != nil || resp.StatusCode != http.StatusUnauthorized {
+				return resp, err
+			}
+			if r.Body != nil && r.GetBody == nil {
+				return resp, err
+			}
+			ts.invalidate()
+			t, terr := ts.TokenContext(r.Context())
+			if terr != nil {
+				return resp, err
+			}
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+			if r.GetBody != nil {
+				if r.Body, err = r.GetBody(); err != nil {
+					return nil, err
+				}
+			}
+			r.Header.Set("Authorization", t.authorization())
+			return next(r)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type NextPageFunc func(rsp *Response) (next *Request, err error)
+
+type ThrottleEvent struct {
+	Page      int
+	Reason    string
+	Delay     time.Duration
+	Remaining int
+}
+
+type Pager struct {
+	MaxDelay   time.Duration
+	MaxRetries int
+
+	next       NextPageFunc
+	pending    *Request
+	current    *Response
+	err        error
+	page       int
+	delay      time.Duration
+	onThrottle []func(ThrottleEvent)
+}
+
+func NewPager(first *Request, next NextPageFunc) *Pager {
+	return &Pager{
+		MaxDelay:   time.Minute,
+		MaxRetries: 5,
+		next:       next,
+		pending:    first,
+	}
+}
+
+func (p *Pager) OnThrottle(fn func(ThrottleEvent)) *Pager {
+	p.onThrottle = append(p.onThrottle, fn)
+	return p
+}
+
+func (p *Pager) throttle(event ThrottleEvent) {
+	for _, fn := range p.onThrottle {
+		fn(event)
+	}
+}
+
+func (p *Pager) Next(ctx context.Context) bool {
+	if p.err != nil || p.pending == nil {
+		return false
+	}
+	if p.current != nil {
+		next, err := p.next(p.current)
+		p.current = nil
+		if err != nil {
+			p.err = err
+			return false
+		}
+		if next == nil {
+			p.pending = nil
+			return false
+		}
+		p.pending = next
+	}
+	p.page++
+	for retries := 0; ; retries++ {
+		if p.err = sleep(ctx, p.delay); p.err != nil {
+			return false
+		}
+		rsp, err := p.pending.Clone().Do(ctx)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		if rsp.StatusCode() == http.StatusTooManyRequests {
+			discard(rsp)
+			if retries >= p.MaxRetries {
+				p.err = rsp.statusError()
+				return false
+			}
+			wait, ok := retryAfter(rsp.Header().Get("Retry-After"))
+			if !ok {
+				wait = p.delay * 2
+				if wait == 0 {
+					wait = time.Second
+				}
+			}
+			p.delay = p.clamp(wait)
+			p.throttle(ThrottleEvent{Page: p.page, Reason: "429", Delay: p.delay, Remaining: 0})
+			continue
+		}
+		p.adapt(rsp)
+		p.current = rsp
+		return true
+	}
+}
+
+func (p *Pager) adapt(rsp *Response) {
+	q, ok := ParseQuota(rsp.Header(), time.Now())
+	if !ok || q.Reset.IsZero() {
+		p.delay /= 2
+		return
+	}
+	low := q.Remaining <= 10
+	if q.Limit > 0 {
+		low = q.Remaining*10 <= q.Limit
+	}
+	window := time.Until(q.Reset)
+	if !low || window <= 0 {
+		p.delay /= 2
+		return
+	}
+	if q.Remaining <= 0 {
+		p.delay = p.clamp(window)
+		p.throttle(ThrottleEvent{Page: p.page, Reason: "quota", Delay: p.delay, Remaining: q.Remaining})
+		return
+	}
+	spread := window / time.Duration(q.Remaining)
+	if spread > p.delay {
+		p.delay = p.clamp(spread)
+		p.throttle(ThrottleEvent{Page: p.page, Reason: "quota", Delay: p.delay, Remaining: q.Remaining})
+		return
+	}
+	p.delay /= 2
+}
+
+func (p *Pager) clamp(d time.Duration) time.Duration {
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+func (p *Pager) Response() *Response {
+	return p.current
+}
+
+func (p *Pager) Page() int {
+	return p.page
+}
+
+func (p *Pager) Err() error {
+	return p.err
+}
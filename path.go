@@ -0,0 +1,69 @@
+package httpr
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var pathParamPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+func (req *Request) PathParam(key, value string) *Request {
+	if req.pathParams == nil {
+		req.pathParams = map[string]string{}
+	}
+	req.pathParams[key] = value
+	return req
+}
+
+func (req *Request) expandPath(uri string) (string, error) {
+	var missing string
+	uri = pathParamPattern.ReplaceAllStringFunc(uri, func(m string) string {
+		key := m[1 : len(m)-1]
+		value, ok := req.pathParams[key]
+		if !ok {
+			if missing == "" {
+				missing = key
+			}
+			return m
+		}
+		return url.PathEscape(value)
+	})
+	if missing != "" {
+		return uri, errors.New("httpr: missing path param " + missing)
+	}
+	return uri, nil
+}
+
+func (s *Service) suggestPath(key string) (best string) {
+	bestDist := len(key)/3 + 2
+	for candidate := range s.paths {
+		d := levenshtein(strings.ToLower(key), strings.ToLower(candidate))
+		if d < bestDist || (d == bestDist && best != "" && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+	return
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
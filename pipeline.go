@@ -0,0 +1,149 @@
+package httpr
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	StageDecompress = "decompress"
+	StageVerify     = "verify"
+	StageSniffGzip  = "sniff-gzip"
+)
+
+type ResponseStage struct {
+	Name string
+	Func func(rsp *Response, body []byte) ([]byte, error)
+}
+
+type ResponsePipeline []ResponseStage
+
+func (p ResponsePipeline) Index(name string) int {
+	for i, stage := range p {
+		if stage.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p ResponsePipeline) Remove(name string) ResponsePipeline {
+	i := p.Index(name)
+	if i < 0 {
+		return p
+	}
+	return append(append(ResponsePipeline{}, p[:i]...), p[i+1:]...)
+}
+
+func (p ResponsePipeline) Insert(i int, stages ...ResponseStage) ResponsePipeline {
+	if i < 0 || i > len(p) {
+		i = len(p)
+	}
+	np := append(ResponsePipeline{}, p[:i]...)
+	np = append(np, stages...)
+	return append(np, p[i:]...)
+}
+
+func (p ResponsePipeline) Move(name string, i int) ResponsePipeline {
+	j := p.Index(name)
+	if j < 0 {
+		return p
+	}
+	return p.Remove(name).Insert(i, p[j])
+}
+
+func (p ResponsePipeline) run(rsp *Response, body []byte) (bs []byte, err error) {
+	bs = body
+	for _, stage := range p {
+		bs, err = stage.Func(rsp, bs)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func DecompressStage() ResponseStage {
+	return ResponseStage{
+		Name: StageDecompress,
+		Func: decompress,
+	}
+}
+
+func VerifyStage(verify func(rsp *Response, body []byte) error) ResponseStage {
+	return ResponseStage{
+		Name: StageVerify,
+		Func: func(rsp *Response, body []byte) ([]byte, error) {
+			return body, verify(rsp, body)
+		},
+	}
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+func SniffGzipStage() ResponseStage {
+	return ResponseStage{
+		Name: StageSniffGzip,
+		Func: sniffGzip,
+	}
+}
+
+func sniffGzip(rsp *Response, body []byte) ([]byte, error) {
+	if !bytes.HasPrefix(body, gzipMagic) {
+		return body, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return body, nil
+	}
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return body, nil
+	}
+	return bs, nil
+}
+
+func decompress(rsp *Response, body []byte) (bs []byte, err error) {
+	if rsp.rsp.Uncompressed {
+		bs = body
+		return
+	}
+	var r io.Reader
+	switch strings.ToLower(rsp.rsp.Header.Get("Content-Encoding")) {
+	case "gzip", "x-gzip":
+		if rsp.req.service != nil && rsp.req.service.pipeline.Index(StageSniffGzip) >= 0 && !bytes.HasPrefix(body, gzipMagic) {
+			bs = body
+			return
+		}
+		r, err = gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return
+		}
+	case "deflate":
+		r = flate.NewReader(bytes.NewReader(body))
+	default:
+		bs = body
+		return
+	}
+	return ioutil.ReadAll(r)
+}
+
+func (s *Service) ResponseStages(stages ...ResponseStage) *Service {
+	s.pipeline = append(ResponsePipeline{}, stages...)
+	return s
+}
+
+func (s *Service) SniffGzip() *Service {
+	if s.pipeline.Index(StageSniffGzip) < 0 {
+		s.pipeline = s.pipeline.Insert(0, SniffGzipStage())
+	}
+	return s
+}
+
+func (s *Service) ResponsePipeline() ResponsePipeline {
+	return append(ResponsePipeline{}, s.pipeline...)
+}
@@ -0,0 +1,56 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const TrafficClassTag = "traffic_class"
+
+var ErrInvalidPriority = errors.New("httpr: priority urgency must be between 0 and 7")
+
+type trafficClassKey struct{}
+
+func (req *Request) Priority(urgency int, incremental bool) *Request {
+	if urgency < 0 || urgency > 7 {
+		return req.fail(ErrInvalidPriority)
+	}
+	req.priority = "u=" + strconv.Itoa(urgency)
+	if incremental {
+		req.priority += ", i"
+	}
+	return req
+}
+
+func (s *Service) TrafficClass(class string) *Service {
+	s.trafficClass = class
+	return s
+}
+
+func (req *Request) TrafficClass(class string) *Request {
+	return req.Tag(TrafficClassTag, class)
+}
+
+func (req *Request) trafficClass() string {
+	if class, ok := req.tags[TrafficClassTag]; ok {
+		return class
+	}
+	if req.service != nil {
+		return req.service.trafficClass
+	}
+	return ""
+}
+
+func (req *Request) classContext(ctx context.Context) context.Context {
+	if class := req.trafficClass(); class != "" {
+		return context.WithValue(ctx, trafficClassKey{}, class)
+	}
+	return ctx
+}
+
+func TrafficClassOf(r *http.Request) string {
+	class, _ := r.Context().Value(trafficClassKey{}).(string)
+	return class
+}
@@ -0,0 +1,102 @@
+package httpr
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+const ProblemJSON = "application/problem+json"
+
+type ProblemError struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+	Response   *StatusError
+}
+
+func (e *ProblemError) Error() string {
+	msg := e.Title
+	if msg == "" {
+		msg = e.Type
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Response != nil {
+		return fmt.Sprintf("httpr: %s %s: %s (%s)", e.Response.Method, e.Response.URL, msg, e.Response.Status)
+	}
+	return fmt.Sprintf("httpr: problem %d: %s", e.Status, msg)
+}
+
+func (e *ProblemError) Unwrap() error {
+	if e.Response == nil {
+		return nil
+	}
+	return e.Response
+}
+
+func (s *Service) DecodeProblems() *Service {
+	s.decodeProblems = true
+	return s
+}
+
+func isProblem(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && strings.EqualFold(mediaType, ProblemJSON)
+}
+
+func (rsp *Response) Problem() (p *ProblemError, ok bool) {
+	if rsp.StatusCode() < 400 || !isProblem(rsp.Header().Get("Content-Type")) {
+		return
+	}
+	bs, err := rsp.Bytes()
+	if err != nil {
+		return
+	}
+	var members map[string]json.RawMessage
+	if json.Unmarshal(bs, &members) != nil {
+		return
+	}
+	p = &ProblemError{Type: "about:blank", Status: rsp.StatusCode(), Response: rsp.statusError()}
+	for key, raw := range members {
+		var err error
+		switch key {
+		case "type":
+			err = json.Unmarshal(raw, &p.Type)
+		case "title":
+			err = json.Unmarshal(raw, &p.Title)
+		case "status":
+			err = json.Unmarshal(raw, &p.Status)
+		case "detail":
+			err = json.Unmarshal(raw, &p.Detail)
+		case "instance":
+			err = json.Unmarshal(raw, &p.Instance)
+		default:
+			var v interface{}
+			if json.Unmarshal(raw, &v) == nil {
+				if p.Extensions == nil {
+					p.Extensions = map[string]interface{}{}
+				}
+				p.Extensions[key] = v
+			}
+		}
+		if err != nil {
+			p.Extensions = nil
+			return nil, false
+		}
+	}
+	ok = true
+	return
+}
+
+func (rsp *Response) failure() error {
+	if p, ok := rsp.Problem(); ok {
+		return p
+	}
+	return rsp.statusError()
+}
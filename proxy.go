@@ -0,0 +1,150 @@
+package httpr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+var ErrNoProxy = errors.New("httpr: no healthy proxy available")
+
+type ProxyStrategy int
+
+const (
+	ProxyRoundRobin ProxyStrategy = iota
+	ProxyPerN
+	ProxyOn429
+)
+
+type proxyEntry struct {
+	url       *url.URL
+	deadUntil time.Time
+}
+
+type ProxyPool struct {
+	mu       sync.Mutex
+	proxies  []*proxyEntry
+	strategy ProxyStrategy
+	every    int
+	count    int
+	current  int
+	cooldown time.Duration
+}
+
+func NewProxyPool(strategy ProxyStrategy, proxies ...string) (*ProxyPool, error) {
+	p := &ProxyPool{
+		strategy: strategy,
+		every:    10,
+		cooldown: time.Minute,
+	}
+	for _, proxy := range proxies {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, err
+		}
+		p.proxies = append(p.proxies, &proxyEntry{url: u})
+	}
+	return p, nil
+}
+
+func (p *ProxyPool) RotateEvery(n int) *ProxyPool {
+	p.every = n
+	return p
+}
+
+func (p *ProxyPool) Cooldown(d time.Duration) *ProxyPool {
+	p.cooldown = d
+	return p
+}
+
+func (p *ProxyPool) alive(i int, now time.Time) bool {
+	return !now.Before(p.proxies[i].deadUntil)
+}
+
+func (p *ProxyPool) advance(now time.Time) bool {
+	for i := 1; i <= len(p.proxies); i++ {
+		j := (p.current + i) % len(p.proxies)
+		if p.alive(j, now) {
+			p.current = j
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ProxyPool) Next() (*url.URL, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.proxies) == 0 {
+		return nil, ErrNoProxy
+	}
+	now := time.Now()
+	switch p.strategy {
+	case ProxyRoundRobin:
+		if p.count > 0 && !p.advance(now) {
+			return nil, ErrNoProxy
+		}
+	case ProxyPerN:
+		if p.every > 0 && p.count > 0 && p.count%p.every == 0 && !p.advance(now) {
+			return nil, ErrNoProxy
+		}
+	}
+	if !p.alive(p.current, now) && !p.advance(now) {
+		return nil, ErrNoProxy
+	}
+	p.count++
+	return p.proxies[p.current].url, nil
+}
+
+func (p *ProxyPool) MarkDead(proxy *url.URL) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.proxies {
+		if e.url.String() == proxy.String() {
+			e.deadUntil = time.Now().Add(p.cooldown)
+		}
+	}
+}
+
+func (p *ProxyPool) rotate() {
+	p.mu.Lock()
+	p.advance(time.Now())
+	p.mu.Unlock()
+}
+
+type proxyChoiceKey struct{}
+
+type proxyChoice struct {
+	url *url.URL
+}
+
+func (p *ProxyPool) Proxy(r *http.Request) (*url.URL, error) {
+	u, err := p.Next()
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := r.Context().Value(proxyChoiceKey{}).(*proxyChoice); ok {
+		c.url = u
+	}
+	return u, nil
+}
+
+func (s *Service) ProxyPool(pool *ProxyPool) *Service {
+	s.transport().Proxy = pool.Proxy
+	return s.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			choice := &proxyChoice{}
+			resp, err := next(r.WithContext(context.WithValue(r.Context(), proxyChoiceKey{}, choice)))
+			switch {
+			case err != nil && choice.url != nil && r.Context().Err() == nil:
+				pool.MarkDead(choice.url)
+			case err == nil && resp.StatusCode == http.StatusTooManyRequests && pool.strategy == ProxyOn429:
+				pool.rotate()
+			}
+			return resp, err
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package httpr
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (req *Request) QueryStruct(v interface{}) *Request {
+	values, err := encodeValues(v, "url")
+	if err != nil {
+		req.fail(err)
+		return req
+	}
+	if req.params == nil {
+		req.params = make(url.Values)
+	}
+	for key, vs := range values {
+		req.params[key] = append(req.params[key], vs...)
+	}
+	return req
+}
+
+func (req *Request) ParamInt(key string, value int64) *Request {
+	return req.Params(key, strconv.FormatInt(value, 10))
+}
+
+func (req *Request) ParamBool(key string, value bool) *Request {
+	return req.Params(key, strconv.FormatBool(value))
+}
+
+func (req *Request) ParamTime(key string, value time.Time, layout string) *Request {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return req.Params(key, value.Format(layout))
+}
+
+func encodeValues(v interface{}, tag string) (values url.Values, err error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		err = errors.New("httpr: expected struct, got " + rv.Kind().String())
+		return
+	}
+	values = url.Values{}
+	err = encodeStruct(values, rv, tag)
+	return
+}
+
+func encodeStruct(values url.Values, rv reflect.Value, tag string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name, opts := parseTag(field.Tag.Get(tag))
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct && indirectType(field.Type) != timeType {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := encodeStruct(values, fv, tag); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if opts["omitempty"] && isEmptyValue(fv) {
+			continue
+		}
+		layout := field.Tag.Get("layout")
+		if err := encodeValue(values, name, fv, layout); err != nil {
+			return fmt.Errorf("httpr: field %s: %v", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func encodeValue(values url.Values, name string, fv reflect.Value, layout string) error {
+	for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	if fv.Type() == timeType {
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		values.Add(name, fv.Interface().(time.Time).Format(layout))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			bs := reflect.MakeSlice(reflect.SliceOf(fv.Type().Elem()), fv.Len(), fv.Len())
+			reflect.Copy(bs, fv)
+			values.Add(name, string(bs.Bytes()))
+			return nil
+		}
+		for i := 0; i < fv.Len(); i++ {
+			if err := encodeValue(values, name, fv.Index(i), layout); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.String:
+		values.Add(name, fv.String())
+	case reflect.Bool:
+		values.Add(name, strconv.FormatBool(fv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values.Add(name, strconv.FormatInt(fv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		values.Add(name, strconv.FormatUint(fv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		values.Add(name, strconv.FormatFloat(fv.Float(), 'f', -1, fv.Type().Bits()))
+	default:
+		if s, ok := fv.Interface().(fmt.Stringer); ok {
+			values.Add(name, s.String())
+			return nil
+		}
+		return errors.New("unsupported kind " + fv.Kind().String())
+	}
+	return nil
+}
+
+func parseTag(tag string) (name string, opts map[string]bool) {
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	opts = map[string]bool{}
+	for _, opt := range parts[1:] {
+		opts[opt] = true
+	}
+	return
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time).IsZero()
+		}
+	}
+	return false
+}
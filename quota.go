@@ -0,0 +1,94 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Quota struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	UpdatedAt time.Time
+}
+
+func (q Quota) Known() bool {
+	return !q.UpdatedAt.IsZero()
+}
+
+func ParseQuota(h http.Header, now time.Time) (q Quota, ok bool) {
+	remaining, ok := headerInt(h, "RateLimit-Remaining", "X-RateLimit-Remaining")
+	if !ok {
+		return
+	}
+	q.Remaining = remaining
+	q.Limit, _ = headerInt(h, "RateLimit-Limit", "X-RateLimit-Limit")
+	if reset, ok := headerInt(h, "RateLimit-Reset", "X-RateLimit-Reset"); ok {
+		if reset > 1e9 {
+			q.Reset = time.Unix(int64(reset), 0)
+		} else {
+			q.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	q.UpdatedAt = now
+	return
+}
+
+func headerInt(h http.Header, keys ...string) (n int, ok bool) {
+	for _, key := range keys {
+		v := h.Get(key)
+		if v == "" {
+			continue
+		}
+		if i := strings.IndexAny(v, ",;"); i >= 0 {
+			v = v[:i]
+		}
+		var err error
+		if n, err = strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			ok = true
+			return
+		}
+	}
+	return
+}
+
+func (s *Service) Quota() Quota {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+	return s.quota
+}
+
+func (s *Service) QuotaDelay(threshold int) *Service {
+	s.quotaDelay = true
+	s.quotaThreshold = threshold
+	return s
+}
+
+func (s *Service) updateQuota(h http.Header) {
+	q, ok := ParseQuota(h, time.Now())
+	if !ok {
+		return
+	}
+	s.quotaMu.Lock()
+	s.quota = q
+	s.quotaMu.Unlock()
+}
+
+func (s *Service) waitQuota(ctx context.Context) error {
+	if !s.quotaDelay {
+		return nil
+	}
+	q := s.Quota()
+	if !q.Known() || q.Remaining > s.quotaThreshold {
+		return nil
+	}
+	wait := time.Until(q.Reset)
+	if wait <= 0 {
+		return nil
+	}
+	defaultLogger.Infof("quota low (%d remaining), delaying %v\n", q.Remaining, wait)
+	return sleep(ctx, wait)
+}
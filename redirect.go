@@ -0,0 +1,111 @@
+package httpr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+const defaultMaxRedirects = 10
+
+type Redirect struct {
+	StatusCode int
+	URL        string
+	Location   string
+	Header     http.Header
+	Body       []byte
+	Truncated  bool
+}
+
+type redirectKey struct{}
+
+type redirectTrace struct {
+	limit int64
+	hops  []Redirect
+}
+
+func noRedirect(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+func (s *Service) FollowRedirects(max int) *Service {
+	s.noRedirects = max <= 0
+	s.maxRedirects = max
+	s.client.CheckRedirect = s.checkRedirect
+	s.resetSNIClients()
+	return s
+}
+
+func (s *Service) RetainRedirects(maxBody int64) *Service {
+	s.redirectCap = maxBody
+	s.client.CheckRedirect = s.checkRedirect
+	s.resetSNIClients()
+	return s
+}
+
+func (req *Request) NoRedirects() *Request {
+	req.noRedirects = true
+	return req
+}
+
+func (s *Service) checkRedirect(r *http.Request, via []*http.Request) error {
+	if s.noRedirects {
+		return http.ErrUseLastResponse
+	}
+	if t, ok := r.Context().Value(redirectKey{}).(*redirectTrace); ok && r.Response != nil {
+		t.record(r.Response)
+	}
+	max := s.maxRedirects
+	if max <= 0 {
+		max = defaultMaxRedirects
+	}
+	if len(via) >= max {
+		return fmt.Errorf("httpr: stopped after %d redirects", max)
+	}
+	return nil
+}
+
+func (req *Request) redirectContext(ctx context.Context) context.Context {
+	if req.service == nil || req.service.redirectCap <= 0 {
+		return ctx
+	}
+	req.redirects = &redirectTrace{limit: req.service.redirectCap}
+	return context.WithValue(ctx, redirectKey{}, req.redirects)
+}
+
+func (t *redirectTrace) record(rsp *http.Response) {
+	hop := Redirect{
+		StatusCode: rsp.StatusCode,
+		Location:   rsp.Header.Get("Location"),
+		Header:     rsp.Header.Clone(),
+	}
+	if rsp.Request != nil {
+		hop.URL = rsp.Request.URL.String()
+	}
+	if rsp.Body != nil {
+		hop.Body, _ = ioutil.ReadAll(io.LimitReader(rsp.Body, t.limit+1))
+		if int64(len(hop.Body)) > t.limit {
+			hop.Body, hop.Truncated = hop.Body[:t.limit], true
+		}
+	}
+	t.hops = append(t.hops, hop)
+}
+
+func (rsp *Response) Redirects() []Redirect {
+	if rsp.req.redirects == nil {
+		return nil
+	}
+	return rsp.req.redirects.hops
+}
+
+func (rsp *Response) IsRedirect() bool {
+	code := rsp.StatusCode()
+	return code >= 300 && code < 400 && code != http.StatusNotModified
+}
+
+func (rsp *Response) Location() (*url.URL, error) {
+	return rsp.rsp.Location()
+}
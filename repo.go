@@ -1,11 +1,19 @@
 package httpr
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrServiceNotFound = errors.New("httpr: service not found")
 
 type mapper interface {
 	Store(key string, service *Service)
 	Load(key string) (s *Service, ok bool)
 	Remove(key string)
+	Range(fn func(key string, s *Service) bool)
 }
 
 type unsafeMapper map[string]*Service
@@ -19,25 +27,42 @@ func (m unsafeMapper) Load(key string) (s *Service, ok bool) {
 	return
 }
 
-type safeMapper sync.Map
+func (m unsafeMapper) Remove(key string) {
+	delete(m, key)
+}
+
+func (m unsafeMapper) Range(fn func(key string, s *Service) bool) {
+	for key, s := range m {
+		if !fn(key, s) {
+			return
+		}
+	}
+}
+
+type safeMapper struct {
+	m sync.Map
+}
 
-func (m safeMapper) Store(key string, service *Service) {
-	sync.Map(m).Store(key, service)
+func (m *safeMapper) Store(key string, service *Service) {
+	m.m.Store(key, service)
 }
 
-func (m safeMapper) Load(key string) (s *Service, ok bool) {
-	if ss, ok := sync.Map(m).Load(key); ok {
-		s = ss.(*Service)
+func (m *safeMapper) Load(key string) (s *Service, ok bool) {
+	v, ok := m.m.Load(key)
+	if ok {
+		s = v.(*Service)
 	}
 	return
 }
 
-func (m safeMapper) Remove(key string) {
-	sync.Map(m).Delete(key)
+func (m *safeMapper) Remove(key string) {
+	m.m.Delete(key)
 }
 
-func (m unsafeMapper) Remove(key string) {
-	delete(m, key)
+func (m *safeMapper) Range(fn func(key string, s *Service) bool) {
+	m.m.Range(func(key, value interface{}) bool {
+		return fn(key.(string), value.(*Service))
+	})
 }
 
 type Repo struct {
@@ -52,6 +77,67 @@ func NewRepo() *Repo {
 
 func NewSafeRepo() *Repo {
 	return &Repo{
-		m: safeMapper(sync.Map{}),
+		m: &safeMapper{},
+	}
+}
+
+func (r *Repo) Register(name string, s *Service) *Repo {
+	if s.name == "" {
+		s.name = name
+	}
+	r.m.Store(name, s)
+	return r
+}
+
+func (r *Repo) Remove(name string) *Repo {
+	r.m.Remove(name)
+	return r
+}
+
+func (r *Repo) Service(name string) (s *Service, err error) {
+	s, ok := r.m.Load(name)
+	if !ok {
+		err = fmt.Errorf("%w: %s", ErrServiceNotFound, name)
+	}
+	return
+}
+
+func (r *Repo) MustService(name string) *Service {
+	s, err := r.Service(name)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (r *Repo) Names() (names []string) {
+	r.m.Range(func(key string, _ *Service) bool {
+		names = append(names, key)
+		return true
+	})
+	sort.Strings(names)
+	return
+}
+
+func (r *Repo) Range(fn func(name string, s *Service) bool) {
+	for _, name := range r.Names() {
+		if s, ok := r.m.Load(name); ok && !fn(name, s) {
+			return
+		}
+	}
+}
+
+var DefaultRepo = NewSafeRepo()
+
+func Register(name string, s *Service) {
+	DefaultRepo.Register(name, s)
+}
+
+func Named(name string) *Service {
+	s, err := DefaultRepo.Service(name)
+	if err != nil {
+		s = NewService(nil)
+		s.err = err
 	}
+	return s
 }
@@ -0,0 +1,252 @@
+package httpr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) (addrs []string, err error)
+}
+
+func (s *Service) Resolver(r Resolver) *Service {
+	s.resolver = r
+	s.transport().DialContext = s.dialContext
+	return s
+}
+
+func (s *Service) dialContext(ctx context.Context, network, addr string) (conn net.Conn, err error) {
+	dial := s.dialer
+	if dial == nil {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+		if s.dialTimeout > 0 {
+			dialer.Timeout = s.dialTimeout
+		}
+		dial = dialer.DialContext
+	}
+	addr = s.pinned(addr)
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || s.resolver == nil || net.ParseIP(host) != nil {
+		return dial(ctx, network, addr)
+	}
+	addrs, err := s.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: host}
+	}
+	if len(addrs) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	for _, ip := range addrs {
+		if conn, err = dial(ctx, network, net.JoinHostPort(ip, port)); err == nil {
+			return
+		}
+	}
+	return
+}
+
+func (req *Request) ResolveTo(addr string) *Request {
+	ip := addr
+	if host, port, err := net.SplitHostPort(addr); err == nil && port != "" {
+		ip = host
+	}
+	if net.ParseIP(ip) == nil {
+		return req.fail(fmt.Errorf("%w: %q", ErrInvalidAddress, addr))
+	}
+	req.resolveTo = addr
+	return req
+}
+
+func (s *Service) Resolve(pins map[string]string) *Service {
+	if s.pins == nil {
+		s.pins = map[string]string{}
+	}
+	for host, addr := range pins {
+		s.pins[strings.ToLower(host)] = addr
+	}
+	s.transport().DialContext = s.dialContext
+	return s
+}
+
+func (s *Service) pinned(addr string) string {
+	if len(s.pins) == 0 {
+		return addr
+	}
+	if pin, ok := s.pins[strings.ToLower(addr)]; ok {
+		return pin
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	pin, ok := s.pins[strings.ToLower(host)]
+	if !ok {
+		return addr
+	}
+	if _, _, err := net.SplitHostPort(pin); err == nil {
+		return pin
+	}
+	return net.JoinHostPort(pin, port)
+}
+
+func (req *Request) HostOverride(host string) *Request {
+	req.hostHeader = host
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	req.sni = host
+	return req
+}
+
+func withResolve(c *http.Client, host, addr string) (*http.Client, error) {
+	t, err := baseTransport(c)
+	if err != nil {
+		return nil, err
+	}
+	t = t.Clone()
+	dial := t.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	t.DisableKeepAlives = true
+	t.DialContext = func(ctx context.Context, network, target string) (net.Conn, error) {
+		h, port, err := net.SplitHostPort(target)
+		if err != nil || h != host {
+			return dial(ctx, network, target)
+		}
+		ip, pinnedPort, err := net.SplitHostPort(addr)
+		if err != nil {
+			ip, pinnedPort = addr, port
+		}
+		return dial(ctx, network, net.JoinHostPort(ip, pinnedPort))
+	}
+	cc := *c
+	cc.Transport = t
+	return &cc, nil
+}
+
+type dohAnswer struct {
+	Type int    `json:"type"`
+	TTL  int    `json:"TTL"`
+	Data string `json:"data"`
+}
+
+type dohResponse struct {
+	Status int         `json:"Status"`
+	Answer []dohAnswer `json:"Answer"`
+}
+
+type dohEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+type DoHResolver struct {
+	URL    string
+	Client *http.Client
+	MinTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]dohEntry
+}
+
+func NewDoHResolver(providerURL string) *DoHResolver {
+	return &DoHResolver{
+		URL:    providerURL,
+		Client: &http.Client{Timeout: 5 * time.Second},
+		MinTTL: 30 * time.Second,
+		cache:  map[string]dohEntry{},
+	}
+}
+
+func (r *DoHResolver) LookupHost(ctx context.Context, host string) (addrs []string, err error) {
+	r.mu.Lock()
+	e, ok := r.cache[host]
+	r.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.addrs, nil
+	}
+	ttl := time.Duration(-1)
+	for _, qtype := range []string{"A", "AAAA"} {
+		answers, qerr := r.query(ctx, host, qtype)
+		if qerr != nil {
+			err = qerr
+			continue
+		}
+		for _, a := range answers {
+			if a.Type != 1 && a.Type != 28 {
+				continue
+			}
+			addrs = append(addrs, a.Data)
+			if d := time.Duration(a.TTL) * time.Second; ttl < 0 || d < ttl {
+				ttl = d
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		if err == nil {
+			err = errors.New("no such host")
+		}
+		return
+	}
+	err = nil
+	if ttl < r.MinTTL {
+		ttl = r.MinTTL
+	}
+	r.mu.Lock()
+	if r.cache == nil {
+		r.cache = map[string]dohEntry{}
+	}
+	r.cache[host] = dohEntry{addrs: addrs, expires: time.Now().Add(ttl)}
+	r.mu.Unlock()
+	return
+}
+
+func (r *DoHResolver) query(ctx context.Context, host, qtype string) (answers []dohAnswer, err error) {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return
+	}
+	q := u.Query()
+	q.Set("name", host)
+	q.Set("type", qtype)
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Accept", "application/dns-json")
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("doh: %s returned status %d", r.URL, resp.StatusCode)
+		return
+	}
+	var dr dohResponse
+	if err = json.NewDecoder(resp.Body).Decode(&dr); err != nil {
+		return
+	}
+	if dr.Status != 0 {
+		err = fmt.Errorf("doh: lookup %s %s: rcode %d", host, qtype, dr.Status)
+		return
+	}
+	answers = dr.Answer
+	return
+}
@@ -0,0 +1,83 @@
+package httpr
+
+import (
+	"net/http"
+	"strings"
+)
+
+var HopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+var SensitiveResponseHeaders = []string{
+	"Set-Cookie",
+	"Set-Cookie2",
+	"Server",
+	"Via",
+	"X-Powered-By",
+	"X-Backend-*",
+	"X-Upstream-*",
+	"X-Internal-*",
+}
+
+type headerFilter struct {
+	allow []string
+	strip []string
+}
+
+func (s *Service) filter() *headerFilter {
+	if s.respHeaders == nil {
+		s.respHeaders = &headerFilter{}
+	}
+	return s.respHeaders
+}
+
+func (s *Service) StripResponseHeaders(headers ...string) *Service {
+	if len(headers) == 0 {
+		headers = append(append([]string(nil), HopByHopHeaders...), SensitiveResponseHeaders...)
+	}
+	f := s.filter()
+	f.strip = append(f.strip, headers...)
+	return s
+}
+
+func (s *Service) AllowResponseHeaders(headers ...string) *Service {
+	f := s.filter()
+	f.allow = append(f.allow, headers...)
+	return s
+}
+
+func (f *headerFilter) apply(h http.Header) {
+	if f == nil {
+		return
+	}
+	if allowed("Connection", f.strip) {
+		for _, v := range h.Values("Connection") {
+			for _, token := range strings.Split(v, ",") {
+				if token = strings.TrimSpace(token); token != "" {
+					h.Del(token)
+				}
+			}
+		}
+	}
+	for key := range h {
+		if len(f.allow) > 0 && key != "Content-Type" && !allowed(key, f.allow) || allowed(key, f.strip) {
+			delete(h, key)
+		}
+	}
+}
+
+func (rsp *Response) filterHeaders() {
+	if rsp == nil || rsp.req.service == nil {
+		return
+	}
+	rsp.req.service.respHeaders.apply(rsp.rsp.Header)
+}
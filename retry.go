@@ -0,0 +1,232 @@
+package httpr
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Attempt struct {
+	Status int
+	Err    error
+	Delay  time.Duration
+}
+
+func newAttempt(rsp *Response, err error, delay time.Duration) Attempt {
+	a := Attempt{Err: err, Delay: delay}
+	if rsp != nil {
+		a.Status = rsp.StatusCode()
+	}
+	return a
+}
+
+type RetryError struct {
+	Attempts []Attempt
+}
+
+func (e *RetryError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "httpr: request failed after %d attempts", len(e.Attempts))
+	for i, a := range e.Attempts {
+		fmt.Fprintf(&b, "; #%d ", i+1)
+		if a.Err != nil {
+			b.WriteString(a.Err.Error())
+		} else {
+			fmt.Fprintf(&b, "status %d", a.Status)
+		}
+		if a.Delay > 0 {
+			fmt.Fprintf(&b, " (retried after %v)", a.Delay)
+		}
+	}
+	return b.String()
+}
+
+func (e *RetryError) Unwrap() []error {
+	var errs []error
+	for _, a := range e.Attempts {
+		if a.Err != nil {
+			errs = append(errs, a.Err)
+		}
+	}
+	return errs
+}
+
+func (e *RetryError) Last() error {
+	for i := len(e.Attempts) - 1; i >= 0; i-- {
+		if e.Attempts[i].Err != nil {
+			return e.Attempts[i].Err
+		}
+	}
+	return nil
+}
+
+type RetryPolicy struct {
+	MaxAttempts        int
+	Delays             []time.Duration
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	Jitter             float64
+	RetryOnStatus      []int
+	RetryOn            []ErrorClass
+	RespectRetryAfter  bool
+	AllowNonIdempotent bool
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       3,
+		BaseDelay:         100 * time.Millisecond,
+		MaxDelay:          10 * time.Second,
+		Jitter:            0.2,
+		RetryOnStatus:     []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		RespectRetryAfter: true,
+	}
+}
+
+func (p *RetryPolicy) attempts() int {
+	if len(p.Delays) > 0 {
+		return len(p.Delays) + 1
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	if len(p.Delays) > 0 {
+		if attempt > len(p.Delays) {
+			attempt = len(p.Delays)
+		}
+		return p.Delays[attempt-1]
+	}
+	d := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(d)
+}
+
+func (p *RetryPolicy) retry(req *Request, attempt int, rsp *Response, err error) bool {
+	if attempt >= p.attempts() {
+		return false
+	}
+	if !p.AllowNonIdempotent && !idempotent(req.req) {
+		return false
+	}
+	if !replayable(req.req) {
+		return false
+	}
+	if err != nil {
+		classes := p.RetryOn
+		if len(req.retryOn) > 0 {
+			classes = req.retryOn
+		}
+		return classMatches(classes, ClassOf(err))
+	}
+	return p.retryStatus(rsp)
+}
+
+func (p *RetryPolicy) retryStatus(rsp *Response) bool {
+	if rsp == nil {
+		return false
+	}
+	for _, code := range p.RetryOnStatus {
+		if rsp.StatusCode() == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) delay(attempt int, rsp *Response) time.Duration {
+	if p.RespectRetryAfter && rsp != nil {
+		if d, ok := retryAfter(rsp.rsp.Header.Get("Retry-After")); ok {
+			if p.MaxDelay > 0 && d > p.MaxDelay {
+				d = p.MaxDelay
+			}
+			return d
+		}
+	}
+	return p.Backoff(attempt)
+}
+
+func classMatches(classes []ErrorClass, class ErrorClass) bool {
+	if len(classes) == 0 {
+		return true
+	}
+	for _, c := range classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func idempotent(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return r.Header.Get("Idempotency-Key") != ""
+}
+
+func replayable(r *http.Request) bool {
+	return r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+}
+
+func retryAfter(v string) (d time.Duration, ok bool) {
+	if v == "" {
+		return
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, secs >= 0
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d = time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return
+}
+
+func (s *Service) Retry(policy *RetryPolicy) *Service {
+	s.retryPolicy = policy
+	return s
+}
+
+func (req *Request) Retry(policy *RetryPolicy) *Request {
+	req.retryPolicy = policy
+	return req
+}
+
+func (req *Request) policy() *RetryPolicy {
+	switch {
+	case req.retryPolicy != nil:
+		return req.retryPolicy
+	case len(req.retries) > 0:
+		return &RetryPolicy{
+			Delays:             req.retries,
+			AllowNonIdempotent: true,
+		}
+	case req.service != nil:
+		return req.service.retryPolicy
+	}
+	return nil
+}
+
+func discard(rsp *Response) {
+	if rsp == nil {
+		return
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(rsp.rsp.Body, 4<<10))
+	rsp.rsp.Body.Close()
+}
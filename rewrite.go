@@ -0,0 +1,111 @@
+package httpr
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+type Rewriter struct {
+	scheme       string
+	host         string
+	prefix       string
+	query        url.Values
+	preserveHost bool
+	match        func(u *url.URL) bool
+}
+
+func NewRewriter() *Rewriter {
+	return &Rewriter{}
+}
+
+func (rw *Rewriter) Host(host string) *Rewriter {
+	if u, err := url.Parse(host); err == nil && u.Scheme != "" && u.Host != "" {
+		rw.scheme, rw.host = u.Scheme, u.Host
+		if p := strings.TrimSuffix(u.Path, "/"); p != "" {
+			rw.prefix = p + rw.prefix
+		}
+		return rw
+	}
+	rw.host = host
+	return rw
+}
+
+func (rw *Rewriter) PathPrefix(prefix string) *Rewriter {
+	rw.prefix += "/" + strings.Trim(prefix, "/")
+	return rw
+}
+
+func (rw *Rewriter) Query(key, value string) *Rewriter {
+	if rw.query == nil {
+		rw.query = url.Values{}
+	}
+	rw.query.Set(key, value)
+	return rw
+}
+
+func (rw *Rewriter) PreserveHost() *Rewriter {
+	rw.preserveHost = true
+	return rw
+}
+
+func (rw *Rewriter) When(match func(u *url.URL) bool) *Rewriter {
+	rw.match = match
+	return rw
+}
+
+func (rw *Rewriter) rewrite(u *url.URL) {
+	if rw.scheme != "" {
+		u.Scheme = rw.scheme
+	}
+	if rw.host != "" {
+		u.Host = rw.host
+	}
+	if rw.prefix != "" {
+		u.Path = joinPath(rw.prefix, u.Path)
+		if u.RawPath != "" {
+			u.RawPath = joinPath(rw.prefix, u.RawPath)
+		}
+	}
+	if len(rw.query) > 0 {
+		q := u.Query()
+		for key, values := range rw.query {
+			q[key] = values
+		}
+		u.RawQuery = q.Encode()
+	}
+}
+
+func joinPath(prefix, p string) string {
+	joined := path.Clean(prefix + "/" + p)
+	if strings.HasSuffix(p, "/") && !strings.HasSuffix(joined, "/") {
+		joined += "/"
+	}
+	return joined
+}
+
+func (rw *Rewriter) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			if rw.match != nil && !rw.match(r.URL) {
+				return next(r)
+			}
+			r = r.Clone(r.Context())
+			switch {
+			case rw.preserveHost:
+				if r.Host == "" {
+					r.Host = r.URL.Host
+				}
+			case r.Host == r.URL.Host:
+				r.Host = ""
+			}
+			rw.rewrite(r.URL)
+			return next(r)
+		}
+	}
+}
+
+func (s *Service) Rewrite(rw *Rewriter) *Service {
+	return s.Use(rw.Middleware())
+}
@@ -0,0 +1,243 @@
+package httpr
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrDisallowedByRobots = errors.New("httpr: disallowed by robots.txt")
+
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsEntry struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	disallow   bool
+	fetchedAt  time.Time
+	lastVisit  time.Time
+}
+
+type Robots struct {
+	UserAgent string
+	TTL       time.Duration
+	Override  func(u *url.URL) (allowed, ok bool)
+
+	mu    sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+func NewRobots(userAgent string) *Robots {
+	return &Robots{
+		UserAgent: userAgent,
+		TTL:       24 * time.Hour,
+		hosts:     map[string]*robotsEntry{},
+	}
+}
+
+func (s *Service) Crawler(robots *Robots) *Service {
+	s.robots = robots
+	return s
+}
+
+func parseRobots(r io.Reader, userAgent string) (rules []robotsRule, crawlDelay time.Duration) {
+	var (
+		groups  []*robotsGroup
+		current *robotsGroup
+		inRules bool
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:i]))
+		value := strings.TrimSpace(line[i+1:])
+		switch key {
+		case "user-agent":
+			if current == nil || inRules {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				inRules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			if value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{pattern: value, allow: key == "allow"})
+		case "crawl-delay":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			if secs, err := strconv.ParseFloat(value, 64); err == nil {
+				current.crawlDelay = time.Duration(secs * float64(time.Second))
+			}
+		}
+	}
+	agent := strings.ToLower(userAgent)
+	if i := strings.IndexAny(agent, "/ "); i >= 0 {
+		agent = agent[:i]
+	}
+	var fallback *robotsGroup
+	for _, g := range groups {
+		for _, a := range g.agents {
+			if a == "*" {
+				if fallback == nil {
+					fallback = g
+				}
+				continue
+			}
+			if agent != "" && strings.Contains(agent, a) {
+				return g.rules, g.crawlDelay
+			}
+		}
+	}
+	if fallback != nil {
+		return fallback.rules, fallback.crawlDelay
+	}
+	return
+}
+
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	return globMatch(strings.TrimSuffix(pattern, "$"), path, anchored)
+}
+
+func globMatch(pattern, s string, anchored bool) bool {
+	for pattern != "" {
+		if pattern[0] == '*' {
+			for i := 0; i <= len(s); i++ {
+				if globMatch(pattern[1:], s[i:], anchored) {
+					return true
+				}
+			}
+			return false
+		}
+		if s == "" || pattern[0] != s[0] {
+			return false
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return !anchored || s == ""
+}
+
+func (e *robotsEntry) allowed(path string) bool {
+	if e.disallow {
+		return false
+	}
+	best, allow := -1, true
+	for _, rule := range e.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || n == best && rule.allow {
+			best, allow = n, rule.allow
+		}
+	}
+	return allow
+}
+
+func (rb *Robots) entry(ctx context.Context, client *http.Client, u *url.URL) (*robotsEntry, error) {
+	key := u.Scheme + "://" + u.Host
+	rb.mu.Lock()
+	e, ok := rb.hosts[key]
+	rb.mu.Unlock()
+	if ok && time.Since(e.fetchedAt) < rb.TTL {
+		return e, nil
+	}
+	e = &robotsEntry{fetchedAt: time.Now()}
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, key+"/robots.txt", nil)
+	if err == nil {
+		r.Header.Set("User-Agent", rb.UserAgent)
+		var resp *http.Response
+		if resp, err = client.Do(r); err == nil {
+			switch {
+			case resp.StatusCode >= 500:
+				e.disallow = true
+			case resp.StatusCode < 300:
+				e.rules, e.crawlDelay = parseRobots(io.LimitReader(resp.Body, 500<<10), rb.UserAgent)
+			}
+			resp.Body.Close()
+		}
+	}
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		e.disallow = true
+		e.fetchedAt = time.Now().Add(-rb.TTL + time.Minute)
+	}
+	rb.mu.Lock()
+	if old, ok := rb.hosts[key]; ok {
+		e.lastVisit = old.lastVisit
+	}
+	if rb.hosts == nil {
+		rb.hosts = map[string]*robotsEntry{}
+	}
+	rb.hosts[key] = e
+	rb.mu.Unlock()
+	return e, nil
+}
+
+func (rb *Robots) check(ctx context.Context, client *http.Client, r *http.Request) error {
+	if r.URL.Path == "/robots.txt" {
+		return nil
+	}
+	if rb.Override != nil {
+		if allowed, ok := rb.Override(r.URL); ok {
+			if !allowed {
+				return ErrDisallowedByRobots
+			}
+			return nil
+		}
+	}
+	e, err := rb.entry(ctx, client, r.URL)
+	if err != nil {
+		return err
+	}
+	path := r.URL.EscapedPath()
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
+	}
+	if !e.allowed(path) {
+		return ErrDisallowedByRobots
+	}
+	if e.crawlDelay <= 0 {
+		return nil
+	}
+	rb.mu.Lock()
+	wait := time.Until(e.lastVisit.Add(e.crawlDelay))
+	if wait < 0 {
+		wait = 0
+	}
+	e.lastVisit = time.Now().Add(wait)
+	rb.mu.Unlock()
+	return sleep(ctx, wait)
+}
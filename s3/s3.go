@@ -0,0 +1,230 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/heramerom/httpr"
+)
+
+const MinPartSize = 5 << 20
+
+type Client struct {
+	service  *httpr.Service
+	signer   *httpr.SigV4
+	endpoint string
+}
+
+func New(service *httpr.Service, endpoint, region string, creds httpr.AWSCredentials) *Client {
+	return &Client{
+		service:  service,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		signer: &httpr.SigV4{
+			Credentials: creds,
+			Region:      region,
+			Service:     "s3",
+		},
+	}
+}
+
+func (c *Client) objectURL(bucket, key string) string {
+	return c.endpoint + "/" + bucket + "/" + strings.TrimPrefix(key, "/")
+}
+
+func (c *Client) Presign(method, bucket, key string, expires time.Duration) (string, error) {
+	return c.signer.Presign(method, c.objectURL(bucket, key), expires)
+}
+
+func (c *Client) PresignGet(bucket, key string, expires time.Duration) (string, error) {
+	return c.Presign(http.MethodGet, bucket, key, expires)
+}
+
+func (c *Client) PresignPut(bucket, key string, expires time.Duration) (string, error) {
+	return c.Presign(http.MethodPut, bucket, key, expires)
+}
+
+func (c *Client) request(ctx context.Context, method, bucket, key string, body []byte) *httpr.Request {
+	hash := httpr.PayloadHash(body)
+	req := c.service.Request(method, c.objectURL(bucket, key)).
+		Context(ctx).
+		BeforeRequest(func(r *http.Request) {
+			c.signer.Sign(r, hash)
+		})
+	if body != nil {
+		req.Body(bytes.NewReader(body))
+	}
+	return req
+}
+
+type Part struct {
+	PartNumber int
+	ETag       string
+}
+
+type initiateResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completeUpload struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []Part   `xml:"Part"`
+}
+
+type Upload struct {
+	client   *Client
+	bucket   string
+	key      string
+	UploadID string
+}
+
+func (c *Client) InitiateUpload(ctx context.Context, bucket, key string) (u *Upload, err error) {
+	rsp, err := c.request(ctx, http.MethodPost, bucket, key, nil).Params("uploads", "").Response()
+	if err != nil {
+		return
+	}
+	if err = expectOK(rsp); err != nil {
+		return
+	}
+	var result initiateResult
+	if err = rsp.ToXML(&result); err != nil {
+		return
+	}
+	u = &Upload{
+		client:   c,
+		bucket:   bucket,
+		key:      key,
+		UploadID: result.UploadID,
+	}
+	return
+}
+
+func (u *Upload) partRequest(ctx context.Context, number int, data []byte) *httpr.Request {
+	return u.client.request(ctx, http.MethodPut, u.bucket, u.key, data).
+		Params("partNumber", strconv.Itoa(number), "uploadId", u.UploadID).
+		Tag("part", strconv.Itoa(number))
+}
+
+func (u *Upload) UploadPart(ctx context.Context, number int, data []byte) (part Part, err error) {
+	rsp, err := u.partRequest(ctx, number, data).Response()
+	if err != nil {
+		return
+	}
+	if err = expectOK(rsp); err != nil {
+		return
+	}
+	part = Part{PartNumber: number, ETag: rsp.Header().Get("ETag")}
+	return
+}
+
+func (u *Upload) Complete(ctx context.Context, parts []Part) (err error) {
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	body, err := xml.Marshal(completeUpload{Parts: parts})
+	if err != nil {
+		return
+	}
+	rsp, err := u.client.request(ctx, http.MethodPost, u.bucket, u.key, body).
+		Params("uploadId", u.UploadID).
+		Response()
+	if err != nil {
+		return
+	}
+	return expectOK(rsp)
+}
+
+func (u *Upload) Abort(ctx context.Context) (err error) {
+	rsp, err := u.client.request(ctx, http.MethodDelete, u.bucket, u.key, nil).
+		Params("uploadId", u.UploadID).
+		Response()
+	if err != nil {
+		return
+	}
+	return expectOK(rsp)
+}
+
+func (c *Client) Upload(ctx context.Context, bucket, key string, r io.Reader, partSize int64, concurrency int) (err error) {
+	if partSize < MinPartSize {
+		partSize = MinPartSize
+	}
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	u, err := c.InitiateUpload(ctx, bucket, key)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			u.Abort(context.Background())
+		}
+	}()
+	var parts []Part
+	number := 0
+	for eof := false; !eof; {
+		var reqs []*httpr.Request
+		for len(reqs) < concurrency {
+			data := make([]byte, partSize)
+			n, rerr := io.ReadFull(r, data)
+			if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+				eof = true
+			} else if rerr != nil {
+				return rerr
+			}
+			if n == 0 && number > 0 {
+				break
+			}
+			number++
+			reqs = append(reqs, u.partRequest(ctx, number, data[:n]))
+			if eof {
+				break
+			}
+		}
+		for result := range httpr.NewGroup(reqs...).AsyncContext(ctx) {
+			if result.Err != nil {
+				err = result.Err
+				continue
+			}
+			if rerr := expectOK(result.Response); rerr != nil {
+				err = rerr
+				continue
+			}
+			n, _ := strconv.Atoi(result.Response.Request().Tags()["part"])
+			parts = append(parts, Part{PartNumber: n, ETag: result.Response.Header().Get("ETag")})
+		}
+		if err != nil {
+			return
+		}
+	}
+	return u.Complete(ctx, parts)
+}
+
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("s3: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func expectOK(rsp *httpr.Response) error {
+	if rsp.StatusCode() >= 200 && rsp.StatusCode() < 300 {
+		return nil
+	}
+	e := &Error{StatusCode: rsp.StatusCode()}
+	if err := rsp.ToXML(e); err != nil {
+		return errors.New("s3: unexpected status " + strconv.Itoa(rsp.StatusCode()))
+	}
+	return e
+}
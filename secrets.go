@@ -0,0 +1,149 @@
+package httpr
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoSecretsProvider = errors.New("httpr: no secrets provider configured")
+
+type SecretsProvider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+type SecretsFunc func(ctx context.Context, name string) (string, error)
+
+func (f SecretsFunc) Get(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+type SecretNotFoundError struct {
+	Name string
+}
+
+func (e *SecretNotFoundError) Error() string {
+	return fmt.Sprintf("httpr: secret %q not found", e.Name)
+}
+
+func EnvSecrets(prefix string) SecretsProvider {
+	return SecretsFunc(func(_ context.Context, name string) (string, error) {
+		key := prefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_", "/", "_").Replace(name))
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			return "", &SecretNotFoundError{Name: name}
+		}
+		return value, nil
+	})
+}
+
+type RotateFunc func(name string)
+
+type secretEntry struct {
+	value   string
+	fetched time.Time
+}
+
+type SecretCache struct {
+	provider SecretsProvider
+	ttl      time.Duration
+
+	mu       sync.Mutex
+	entries  map[string]secretEntry
+	onRotate []RotateFunc
+}
+
+func NewSecretCache(provider SecretsProvider, ttl time.Duration) *SecretCache {
+	return &SecretCache{provider: provider, ttl: ttl, entries: map[string]secretEntry{}}
+}
+
+func (c *SecretCache) OnRotate(fn RotateFunc) *SecretCache {
+	c.mu.Lock()
+	c.onRotate = append(c.onRotate, fn)
+	c.mu.Unlock()
+	return c
+}
+
+func (c *SecretCache) Invalidate(names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(names) == 0 {
+		for name, e := range c.entries {
+			e.fetched = time.Time{}
+			c.entries[name] = e
+		}
+		return
+	}
+	for _, name := range names {
+		if e, ok := c.entries[name]; ok {
+			e.fetched = time.Time{}
+			c.entries[name] = e
+		}
+	}
+}
+
+func (c *SecretCache) Get(ctx context.Context, name string) (value string, err error) {
+	c.mu.Lock()
+	e, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && !e.fetched.IsZero() && (c.ttl <= 0 || time.Since(e.fetched) < c.ttl) {
+		value = e.value
+		return
+	}
+	if value, err = c.provider.Get(ctx, name); err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.entries[name] = secretEntry{value: value, fetched: time.Now()}
+	hooks := c.onRotate
+	c.mu.Unlock()
+	if ok && e.value != value {
+		for _, fn := range hooks {
+			fn(name)
+		}
+	}
+	return
+}
+
+func (s *Service) Secrets(provider SecretsProvider) *Service {
+	s.secrets = provider
+	return s
+}
+
+func (s *Service) secret(ctx context.Context, name string) (string, error) {
+	if s.secrets == nil {
+		return "", ErrNoSecretsProvider
+	}
+	return s.secrets.Get(ctx, name)
+}
+
+func (s *Service) HeaderFromSecret(header, name string) *Service {
+	return s.credential(header, func(ctx context.Context) (string, error) {
+		return s.secret(ctx, name)
+	})
+}
+
+func (s *Service) BearerFromSecret(name string) *Service {
+	return s.credential("Authorization", func(ctx context.Context) (string, error) {
+		token, err := s.secret(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	})
+}
+
+func (s *Service) BasicAuthFromSecret(user, name string) *Service {
+	return s.credential("Authorization", func(ctx context.Context) (string, error) {
+		pass, err := s.secret(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass)), nil
+	})
+}
@@ -1,35 +1,97 @@
 package httpr
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Conf struct {
-	Timeout time.Duration
-	Debug   bool
+	Timeout        time.Duration
+	Debug          bool
+	DebugCurl      bool
+	SpoolThreshold int64
+	SpoolDir       string
+
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	ForceAttemptHTTP2   bool
+	DisableKeepAlives   bool
 }
 
-type BeforeFunc func(r *Request) (stop bool)
+type BeforeFunc func(r *Request) error
 type BeforeRequestHook func(r *http.Request)
 type AfterFunc func(r *Request, rsp *Response) (stop bool)
 
 type Service struct {
-	host          string
-	hosts         []string
-	paths         map[string]string
-	header        http.Header
-	conf          Conf
-	client        *http.Client
-	beforeRequest []BeforeRequestHook
-	afterHooks    []AfterFunc
+	name             string
+	host             string
+	hosts            []string
+	paths            map[string]string
+	header           http.Header
+	conf             Conf
+	client           *http.Client
+	sniClients       sync.Map
+	before           []BeforeFunc
+	beforeRequest    []BeforeRequestHook
+	afterHooks       []AfterFunc
+	after            []AfterHook
+	pipeline         ResponsePipeline
+	classifier       Classifier
+	auditor          *Auditor
+	costHooks        []CostFunc
+	retryPolicy      *RetryPolicy
+	balancer         *balancer
+	middlewares      []Middleware
+	credentials      []credential
+	sniffCharset     bool
+	cacheDecoded     bool
+	robots           *Robots
+	redact           *redactor
+	limiter          *HostLimiter
+	transforms       map[string][]BodyTransform
+	trafficClass     string
+	resolver         Resolver
+	metrics          MetricsSink
+	tracer           Tracer
+	dialTimeout      time.Duration
+	dialer           DialFunc
+	noRedirects      bool
+	maxRedirects     int
+	redirectCap      int64
+	errorDecoder     ErrorDecoder
+	decodeProblems   bool
+	acceptEncoding   string
+	contentDecoders  map[string]ContentDecoder
+	compressName     string
+	compressor       ContentEncoder
+	signer           Signer
+	requestLog       *requestLog
+	secrets          SecretsProvider
+	respHeaders      *headerFilter
+	maxResponseBytes int64
+	pins             map[string]string
+	err              error
+
+	stats serviceStats
+
+	quotaMu        sync.Mutex
+	quota          Quota
+	quotaDelay     bool
+	quotaThreshold int
 }
 
 func NewService(conf *Conf) *Service {
@@ -39,17 +101,25 @@ func NewService(conf *Conf) *Service {
 	if conf != nil {
 		c = *conf
 	}
-	return &Service{
+	s := &Service{
 		conf: c,
 		client: &http.Client{
 			Timeout: c.Timeout,
 		},
 	}
+	s.tune(c)
+	return s
+}
+
+func (s *Service) Host(host string) *Service {
+	s.host = strings.TrimSuffix(host, "/")
+	return s
 }
 
 func (s *Service) Paths(methodAndPath ...string) *Service {
 	if len(methodAndPath)%2 != 0 {
-		panic("method and path are not pairs")
+		s.err = ErrUnpairedPaths
+		return s
 	}
 	if s.paths == nil {
 		s.paths = map[string]string{}
@@ -61,11 +131,17 @@ func (s *Service) Paths(methodAndPath ...string) *Service {
 }
 
 func (s *Service) RawHeader(key, value string) *Service {
+	if s.header == nil {
+		s.header = http.Header{}
+	}
 	s.header[key] = []string{value}
 	return s
 }
 
 func (s *Service) Header(key, value string) *Service {
+	if s.header == nil {
+		s.header = http.Header{}
+	}
 	s.header.Add(key, value)
 	return s
 }
@@ -73,19 +149,53 @@ func (s *Service) Header(key, value string) *Service {
 func (s *Service) Method(method string, uriKey string) *Request {
 	uri, ok := s.paths[uriKey]
 	if !ok {
-		panic("not found" + uriKey)
+		err := fmt.Errorf("%w: %s", ErrPathNotFound, uriKey)
+		if suggestion := s.suggestPath(uriKey); suggestion != "" {
+			err = fmt.Errorf("%w: %s (did you mean %s?)", ErrPathNotFound, uriKey, suggestion)
+		}
+		return s.Request(method, "").fail(err)
+	}
+	req := s.Request(method, uri)
+	req.endpoint = uriKey
+	return req
+}
+
+func (s *Service) MustMethod(method string, uriKey string) *Request {
+	req := s.Method(method, uriKey)
+	if req.err != nil {
+		panic(req.err)
 	}
-	return s.Request(method, uri)
+	return req
+}
+
+func (s *Service) Err() error {
+	return s.err
 }
 
 func (s *Service) Request(method, uri string) *Request {
-	return &Request{
+	req := &Request{
 		method:  method,
-		header:  s.header,
+		header:  s.header.Clone(),
 		conf:    s.conf,
-		uri:     s.host + uri,
+		uri:     uri,
 		service: s,
+		err:     s.err,
+	}
+	if !isAbsoluteURL(uri) {
+		req.host = s.host
 	}
+	for key := range req.header {
+		if req.inherited == nil {
+			req.inherited = map[string]bool{}
+		}
+		req.inherited[key] = true
+	}
+	return req
+}
+
+func isAbsoluteURL(uri string) bool {
+	u, err := url.Parse(uri)
+	return err == nil && u.IsAbs() && u.Host != ""
 }
 
 func (s *Service) Get(uri string) *Request {
@@ -101,18 +211,59 @@ func (s *Service) Rest(method string, params ...string) *Request {
 }
 
 type Request struct {
-	uri           string
-	conf          Conf
-	method        string
-	retries       []time.Duration
-	header        http.Header
-	service       *Service
-	startAt       time.Time
-	endAt         time.Time
-	params        url.Values
-	req           *http.Request
-	beforeRequest []BeforeRequestHook
-	afterHooks    []AfterFunc
+	host             string
+	endpoint         string
+	hostHeader       string
+	sni              string
+	resolveTo        string
+	maxResponseBytes int64
+	uri              string
+	conf             Conf
+	method           string
+	retries          []time.Duration
+	retryOn          []ErrorClass
+	retryPolicy      *RetryPolicy
+	header           http.Header
+	service          *Service
+	httpClient       *http.Client
+	startAt          time.Time
+	endAt            time.Time
+	params           url.Values
+	pathParams       map[string]string
+	overrideQuery    bool
+	body             io.Reader
+	bodyBytes        []byte
+	contentType      string
+	actor            string
+	tags             map[string]string
+	limiter          *HostLimiter
+	cookies          []*http.Cookie
+	priority         string
+	timeout          time.Duration
+	expectTypes      []string
+	expectStatus     []int
+	expectSuccess    bool
+	noRedirects      bool
+	redirects        *redirectTrace
+	compress         bool
+	compressed       string
+	decodeContent    bool
+	cancel           context.CancelFunc
+	ctx              context.Context
+	used             int32
+	attempts         int
+	signer           Signer
+	dropped          map[string]bool
+	inherited        map[string]bool
+	cacheDecoded     bool
+	err              error
+	req              *http.Request
+	reqCtx           context.Context
+	hookRetries      int
+	before           []BeforeFunc
+	beforeRequest    []BeforeRequestHook
+	afterHooks       []AfterFunc
+	after            []AfterHook
 }
 
 func NewRequest(method string, uri string) *Request {
@@ -125,14 +276,54 @@ func NewRequest(method string, uri string) *Request {
 	}
 }
 
+func (req *Request) UseClient(c *http.Client) *Request {
+	req.httpClient = c
+	return req
+}
+
+func (req *Request) Host(host string) *Request {
+	req.host = strings.TrimSuffix(host, "/")
+	return req
+}
+
+func (req *Request) HostHeader(host string) *Request {
+	req.hostHeader = host
+	return req
+}
+
+func (req *Request) SNI(name string) *Request {
+	req.sni = name
+	return req
+}
+
+func (req *Request) Context(ctx context.Context) *Request {
+	req.ctx = ctx
+	return req
+}
+
+func (req *Request) context() context.Context {
+	if req.ctx != nil {
+		return req.ctx
+	}
+	return context.Background()
+}
+
 func (req *Request) RetryDelay(retires ...time.Duration) *Request {
 	req.retries = retires
 	return req
 }
 
+func (req *Request) fail(err error) *Request {
+	if req.err == nil {
+		req.err = err
+	}
+	return req
+}
+
 func (req *Request) Params(params ...string) *Request {
 	if len(params)%2 != 0 {
-		panic("params error")
+		req.fail(ErrUnpairedParams)
+		return req
 	}
 	if req.params == nil {
 		req.params = make(url.Values)
@@ -143,6 +334,11 @@ func (req *Request) Params(params ...string) *Request {
 	return req
 }
 
+func (req *Request) OverrideQuery() *Request {
+	req.overrideQuery = true
+	return req
+}
+
 func (req *Request) RawHeader(key, value string) *Request {
 	if req.header == nil {
 		req.header = map[string][]string{}
@@ -155,6 +351,7 @@ func (req *Request) Header(key, value string) *Request {
 	if req.header == nil {
 		req.header = map[string][]string{}
 	}
+	req.override(key)
 	req.header.Add(key, value)
 	return req
 }
@@ -169,27 +366,147 @@ func (req *Request) AfterExec(hooks ...AfterFunc) *Request {
 	return req
 }
 
+func (req *Request) requestContext(ctx context.Context) context.Context {
+	return req.redirectContext(req.timeoutContext(req.classContext(req.limitContext(ctx))))
+}
+
+func (req *Request) rebind(ctx context.Context) {
+	if req.req == nil || req.reqCtx == ctx {
+		return
+	}
+	if req.cancel != nil {
+		req.cancel()
+	}
+	req.reqCtx = ctx
+	req.req = req.req.WithContext(req.requestContext(ctx))
+}
+
 func (req *Request) Request() (r *http.Request, err error) {
 	if req.req != nil {
 		r = req.req
 		return
 	}
+	if req.err != nil {
+		err = req.err
+		return
+	}
+	if err = req.runBefore(); err != nil {
+		return
+	}
 	if req.method == "" {
 		req.method = http.MethodGet
 	}
-	r, err = http.NewRequest(req.method, req.uri, nil)
+	uri, err := req.url()
 	if err != nil {
 		return
 	}
+	if err = req.compressBody(); err != nil {
+		return
+	}
+	req.reqCtx = req.context()
+	r, err = http.NewRequestWithContext(req.requestContext(req.reqCtx), req.method, uri, req.body)
+	if err != nil {
+		return
+	}
+	for key, values := range req.header {
+		r.Header[key] = append([]string(nil), values...)
+	}
+	if req.contentType != "" && r.Header.Get("Content-Type") == "" {
+		r.Header.Set("Content-Type", req.contentType)
+	}
+	for _, c := range req.cookies {
+		r.AddCookie(c)
+	}
+	if req.priority != "" {
+		r.Header.Set("Priority", req.priority)
+	}
+	if req.hostHeader != "" {
+		r.Host = req.hostHeader
+	}
+	req.negotiateEncoding(r)
+	req.layerHeaders(r)
 	req.req = r
 	return
 }
 
+func (req *Request) String() string {
+	uri, err := req.url()
+	if err != nil {
+		uri = req.host + req.uri
+	}
+	method := req.method
+	if method == "" {
+		method = http.MethodGet
+	}
+	return method + " " + uri
+}
+
+func (req *Request) url() (uri string, err error) {
+	uri, err = req.expandPath(req.uri)
+	if err != nil {
+		return
+	}
+	if req.host != "" && !isAbsoluteURL(uri) {
+		uri = req.host + uri
+	}
+	if len(req.params) == 0 {
+		return
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return
+	}
+	query := u.Query()
+	for key, values := range req.params {
+		if req.overrideQuery {
+			query[key] = values
+			continue
+		}
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	uri = u.String()
+	return
+}
+
 func (req *Request) _do(r *http.Request) (rsp *Response, err error) {
-	resp, err := req.service.client.Do(r)
+	if req.service != nil {
+		if err = req.service.waitQuota(r.Context()); err != nil {
+			return
+		}
+		if req.service.robots != nil {
+			if err = req.service.robots.check(r.Context(), req.service.client, r); err != nil {
+				return
+			}
+		}
+	}
+	if err = req.authorize(r); err != nil {
+		return
+	}
+	if l := req.hostLimiter(); l != nil {
+		var release func()
+		if release, err = l.Acquire(r.Context(), r.URL.Host); err != nil {
+			return
+		}
+		defer release()
+	}
+	start := time.Now()
+	resp, err := req.roundTrip()(r)
 	if err != nil {
+		err = req.classify(err)
+		return
+	}
+	req.decodeResponse(r, resp)
+	if err = req.limitBody(resp); err != nil {
+		resp.Body.Close()
 		return
 	}
+	req.trackCost(r, resp, start)
+	if req.service != nil {
+		req.service.updateQuota(resp.Header)
+	}
 	rsp = &Response{
 		req: req,
 		rsp: resp,
@@ -197,13 +514,42 @@ func (req *Request) _do(r *http.Request) (rsp *Response, err error) {
 	return
 }
 
-func (req *Request) client() *http.Client {
-	if req.service != nil {
-		return req.service.client
+func (req *Request) client() (c *http.Client, err error) {
+	switch {
+	case req.httpClient != nil:
+		c = req.httpClient
+		if req.sni != "" {
+			if c, err = withServerName(c, req.sni); err != nil {
+				return
+			}
+			c.Transport.(*http.Transport).DisableKeepAlives = true
+		}
+	case req.service != nil:
+		c = req.service.client
+		if req.sni != "" {
+			if c, err = req.service.sniClient(req.sni); err != nil {
+				return
+			}
+		}
+	default:
+		c = defaultClient(req.conf.Timeout, req.sni)
 	}
-	return &http.Client{
-		Timeout: req.conf.Timeout,
+	if req.resolveTo != "" && req.req != nil {
+		if c, err = withResolve(c, req.req.URL.Hostname(), req.resolveTo); err != nil {
+			return
+		}
 	}
+	if req.timeout > 0 || req.noRedirects {
+		cc := *c
+		if req.timeout > 0 {
+			cc.Timeout = 0
+		}
+		if req.noRedirects {
+			cc.CheckRedirect = noRedirect
+		}
+		c = &cc
+	}
+	return
 }
 
 func (req *Request) do() (rsp *Response, err error) {
@@ -213,18 +559,60 @@ func (req *Request) do() (rsp *Response, err error) {
 	}
 	req.doBeforeRequestHooks(r)
 	req.startAt = time.Now()
-	rsp, err = req._do(r)
-	if err == nil {
-		return
-	}
-	for _, wait := range req.retries {
-		time.Sleep(wait)
-		rsp, err = req._do(r)
-		if err != nil {
+	policy := req.policy()
+	var attempts []Attempt
+	var exhausted bool
+	defer func() {
+		req.releaseTimeout(rsp, err)
+	}()
+	defer func() {
+		if (err != nil || exhausted) && len(attempts) > 0 {
+			attempts = append(attempts, newAttempt(rsp, err, 0))
+			err = &RetryError{Attempts: attempts}
+		}
+	}()
+	r, span := req.startSpan(r)
+	defer func() {
+		span.SetAttributes(map[string]interface{}{
+			"http.retry_count": len(attempts),
+			"http.duration_ms": time.Since(req.startAt).Milliseconds(),
+		})
+		endSpan(span, rsp, err)
+	}()
+	target := *r.URL
+	for attempt := 1; ; attempt++ {
+		req.attempts = attempt
+		ar, aspan := req.startAttemptSpan(r, attempt)
+		rsp, err = req.send(ar, target)
+		endSpan(aspan, rsp, err)
+		if policy == nil || !policy.retry(req, attempt, rsp, err) {
+			exhausted = err == nil && policy != nil && policy.retryStatus(rsp)
+			return
+		}
+		wait := policy.delay(attempt, rsp)
+		attempts = append(attempts, newAttempt(rsp, err, wait))
+		discard(rsp)
+		if err = sleep(r.Context(), wait); err != nil {
+			rsp = nil
 			return
 		}
+		if r.GetBody != nil {
+			if r.Body, err = r.GetBody(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return
 }
 
 func (req *Request) doBeforeRequestHooks(r *http.Request) {
@@ -257,11 +645,53 @@ func (req *Request) doAfterHooks(rsp *Response) (stop bool) {
 }
 
 func (req *Request) Response() (rsp *Response, err error) {
+	return req.Do(req.context())
+}
+
+func (req *Request) Do(ctx context.Context) (rsp *Response, err error) {
+	if ctx == nil {
+		err = ErrNilContext
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&req.used, 0, 1) {
+		err = ErrRequestReused
+		return
+	}
+	req.ctx = ctx
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	req.rebind(ctx)
+	done := req.track()
 	req.startAt = time.Now()
 	rsp, err = req.do()
+	if err == nil {
+		rsp.filterHeaders()
+		if err = rsp.precondition(); err == nil {
+			err = rsp.decodeError()
+		}
+	}
+	if err == nil {
+		err = req.expect(rsp)
+	}
+	if err == nil && req.conf.SpoolThreshold > 0 {
+		err = rsp.spool(req.conf.SpoolThreshold, req.conf.SpoolDir)
+	}
 	req.endAt = time.Now()
+	if req.conf.Debug {
+		req.debug(rsp, err)
+	}
+	if req.conf.DebugCurl {
+		req.debugCurl(rsp, err)
+	}
+	if req.service != nil && req.service.auditor != nil {
+		req.service.auditor.record(req, rsp, err)
+	}
+	done(rsp, err)
+	req.observe(rsp, err)
+	req.logRequest(rsp, err)
 	req.doAfterHooks(rsp)
-	return
+	return req.afterAction(rsp, err)
 }
 
 type Response struct {
@@ -269,56 +699,91 @@ type Response struct {
 	req  *Request
 	body []byte
 	err  error
+	fail error
 	dump bool
+	tees []io.Writer
+
+	raw       []byte
+	spoolFile string
+
+	decodedMu sync.Mutex
+	decoded   map[decodedKey]reflect.Value
 }
 
 func (rsp *Response) StatusCode() int {
 	return rsp.rsp.StatusCode
 }
 
+func (rsp *Response) Header() http.Header {
+	return rsp.rsp.Header
+}
+
+func (rsp *Response) Request() *Request {
+	return rsp.req
+}
+
 func (rsp *Response) Bytes() (bs []byte, err error) {
 	if rsp.body != nil || rsp.err != nil {
 		bs = rsp.body
 		err = rsp.err
 		return
 	}
-	bs, err = ioutil.ReadAll(rsp.rsp.Body)
+	body, err := rsp.rawReader()
 	if err != nil {
 		return
 	}
-	defer rsp.rsp.Body.Close()
+	defer body.Close()
+	bs, err = ioutil.ReadAll(body)
+	if err == nil && rsp.req.service != nil {
+		bs, err = rsp.req.service.pipeline.run(rsp, bs)
+	}
+	rsp.body, rsp.err = bs, err
 	return
 }
 
-func (rsp *Response) ToJson(obj interface{}) (err error) {
-	bs, err := rsp.Bytes()
-	if err != nil {
-		return
+func (rsp *Response) Tee(w io.Writer) *Response {
+	if rsp.body != nil || rsp.buffered() {
+		if r, err := rsp.Reader(); err == nil {
+			io.Copy(w, r)
+			r.Close()
+		}
+		return rsp
 	}
-	err = json.Unmarshal(bs, obj)
-	return
+	rsp.tees = append(rsp.tees, w)
+	return rsp
+}
+
+func (rsp *Response) ToJson(obj interface{}) (err error) {
+	return rsp.cachedDecode("json", obj, func(bs []byte) error {
+		return json.Unmarshal(bs, obj)
+	})
 }
 
 func (rsp *Response) ToXML(obj interface{}) (err error) {
-	bs, err := rsp.Bytes()
-	if err != nil {
-		return
-	}
-	err = xml.Unmarshal(bs, obj)
-	return
+	return rsp.cachedDecode("xml", obj, func(bs []byte) error {
+		return xml.Unmarshal(bs, obj)
+	})
 }
 
 func (rsp *Response) Dump() []byte {
-	requestBytes, err := httputil.DumpRequest(rsp.req.req, true)
+	r, err := rsp.req.dumpable()
+	if err != nil {
+		return nil
+	}
+	requestBytes, err := httputil.DumpRequest(r, true)
+	if err != nil {
+		return nil
+	}
+	resp, err := rsp.dumpable()
 	if err != nil {
 		return nil
 	}
-	responseBytes, err := httputil.DumpResponse(rsp.rsp, true)
+	responseBytes, err := httputil.DumpResponse(resp, true)
 	if err != nil {
 		return nil
 	}
-	summary := []byte(fmt.Sprintf("\nSummary: start at %s, end at %s, cost %v\n", rsp.req.startAt, rsp.req.endAt, rsp.req.endAt.Sub(rsp.req.endAt)))
+	summary := []byte(fmt.Sprintf("\nSummary: start at %s, end at %s, cost %v\n", rsp.req.startAt, rsp.req.endAt, rsp.req.endAt.Sub(rsp.req.startAt)))
 	bs := append(requestBytes, responseBytes...)
 	bs = append(bs, summary...)
-	return bs
+	return rsp.req.redactor().apply(bs)
 }
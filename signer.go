@@ -0,0 +1,150 @@
+package httpr
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Signer interface {
+	Sign(r *http.Request, body []byte) error
+}
+
+var ErrBodyNotReplayable = errors.New("httpr: request body cannot be read for signing")
+
+type UnsignedPayloadSigner interface {
+	SignUnsignedPayload(r *http.Request) error
+}
+
+type SignerFunc func(r *http.Request, body []byte) error
+
+func (f SignerFunc) Sign(r *http.Request, body []byte) error {
+	return f(r, body)
+}
+
+func (s *Service) Signer(signer Signer) *Service {
+	s.signer = signer
+	return s
+}
+
+func (req *Request) Signer(signer Signer) *Request {
+	req.signer = signer
+	return req
+}
+
+func (req *Request) requestSigner() Signer {
+	if req.signer != nil {
+		return req.signer
+	}
+	if req.service != nil {
+		return req.service.signer
+	}
+	return nil
+}
+
+func requestBody(r *http.Request) (body []byte, replayable bool, err error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true, nil
+	}
+	if r.GetBody == nil {
+		return nil, false, nil
+	}
+	rc, err := r.GetBody()
+	if err != nil {
+		return
+	}
+	defer rc.Close()
+	body, err = ioutil.ReadAll(rc)
+	replayable = err == nil
+	return
+}
+
+func (req *Request) sign(next RoundTripFunc) RoundTripFunc {
+	signer := req.requestSigner()
+	if signer == nil {
+		return next
+	}
+	return func(r *http.Request) (*http.Response, error) {
+		body, replayable, err := requestBody(r)
+		if err != nil {
+			return nil, err
+		}
+		r = r.Clone(r.Context())
+		if replayable {
+			err = signer.Sign(r, body)
+		} else if u, ok := signer.(UnsignedPayloadSigner); ok {
+			err = u.SignUnsignedPayload(r)
+		} else {
+			err = ErrBodyNotReplayable
+		}
+		if err != nil {
+			return nil, err
+		}
+		return next(r)
+	}
+}
+
+type sigV4Signer struct {
+	v *SigV4
+}
+
+func (v *SigV4) Signer() Signer {
+	return sigV4Signer{v: v}
+}
+
+func (s sigV4Signer) Sign(r *http.Request, body []byte) error {
+	s.v.Sign(r, PayloadHash(body))
+	return nil
+}
+
+func (s sigV4Signer) SignUnsignedPayload(r *http.Request) error {
+	s.v.Sign(r, UnsignedPayload)
+	return nil
+}
+
+type HMACSigner struct {
+	KeyID   string
+	Secret  []byte
+	Headers []string
+	Now     func() time.Time
+}
+
+const hmacAlgorithm = "HMAC-SHA256"
+
+func (h *HMACSigner) Sign(r *http.Request, body []byte) error {
+	now := time.Now
+	if h.Now != nil {
+		now = h.Now
+	}
+	r.Header.Set("X-Date", now().UTC().Format(time.RFC3339))
+	r.Header.Set("X-Content-Sha256", PayloadHash(body))
+	names := []string{"x-content-sha256", "x-date"}
+	for _, name := range h.Headers {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	lines := []string{r.Method, r.URL.RequestURI()}
+	for _, name := range names {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+			if value == "" {
+				value = r.URL.Host
+			}
+		}
+		lines = append(lines, name+":"+strings.TrimSpace(value))
+	}
+	mac := hmac.New(sha256.New, h.Secret)
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	r.Header.Set("Authorization", hmacAlgorithm+
+		" KeyId="+h.KeyID+
+		", SignedHeaders="+strings.Join(names, ";")+
+		", Signature="+hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
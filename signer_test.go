@@ -0,0 +1,66 @@
+package httpr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACSigner(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	r, err := http.NewRequest(http.MethodPost, "https://api.example.com/v1/items?x=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	signer := &HMACSigner{
+		KeyID:   "key-1",
+		Secret:  []byte("secret"),
+		Headers: []string{"Host", "Content-Type"},
+		Now: func() time.Time {
+			return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		},
+	}
+	if err = signer.Sign(r, body); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Header.Get("X-Content-Sha256"), "015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862"; got != want {
+		t.Errorf("X-Content-Sha256 = %q, want %q", got, want)
+	}
+	want := "HMAC-SHA256 KeyId=key-1, SignedHeaders=content-type;host;x-content-sha256;x-date, " +
+		"Signature=4e3a6aa44587a18679158bf79e792a3ea3bbc59ecd6f4f5c8dacc852384a3644"
+	if got := r.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+}
+
+func TestSignUnreplayableBody(t *testing.T) {
+	var signed *http.Request
+	next := func(r *http.Request) (*http.Response, error) {
+		signed = r
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}
+	newRequest := func() *http.Request {
+		r, err := http.NewRequest(http.MethodPut, "https://examplebucket.s3.amazonaws.com/test.txt", io.NopCloser(strings.NewReader("data")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	req := NewRequest(http.MethodPut, "").Signer(exampleSigV4().Signer())
+	if _, err := req.sign(next)(newRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if got := signed.Header.Get("X-Amz-Content-Sha256"); got != UnsignedPayload {
+		t.Errorf("X-Amz-Content-Sha256 = %q, want %q", got, UnsignedPayload)
+	}
+
+	req = NewRequest(http.MethodPut, "").Signer(&HMACSigner{KeyID: "key-1", Secret: []byte("secret")})
+	if _, err := req.sign(next)(newRequest()); !errors.Is(err, ErrBodyNotReplayable) {
+		t.Errorf("err = %v, want %v", err, ErrBodyNotReplayable)
+	}
+}
@@ -0,0 +1,199 @@
+package httpr
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat  = "20060102T150405Z"
+	sigV4DateFormat  = "20060102"
+	UnsignedPayload  = "UNSIGNED-PAYLOAD"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+type SigV4 struct {
+	Credentials AWSCredentials
+	Region      string
+	Service     string
+	Now         func() time.Time
+}
+
+func PayloadHash(body []byte) string {
+	if len(body) == 0 {
+		return emptyPayloadHash
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func (v *SigV4) now() time.Time {
+	if v.Now != nil {
+		return v.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+func (v *SigV4) scope(t time.Time) string {
+	return t.Format(sigV4DateFormat) + "/" + v.Region + "/" + v.Service + "/aws4_request"
+}
+
+func (v *SigV4) Sign(r *http.Request, payloadHash string) {
+	t := v.now()
+	if payloadHash == "" {
+		payloadHash = UnsignedPayload
+	}
+	r.Header.Set("X-Amz-Date", t.Format(sigV4TimeFormat))
+	r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if v.Credentials.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", v.Credentials.SessionToken)
+	}
+	r.URL.RawPath = awsEscape(r.URL.Path, false)
+	headers, signedHeaders := canonicalHeaders(r)
+	canonical := strings.Join([]string{
+		r.Method,
+		canonicalPath(r.URL),
+		canonicalQuery(r.URL.Query()),
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	signature := v.signature(t, canonical)
+	r.Header.Set("Authorization", sigV4Algorithm+
+		" Credential="+v.Credentials.AccessKeyID+"/"+v.scope(t)+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+signature)
+}
+
+func (v *SigV4) Presign(method, rawurl string, expires time.Duration) (string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	t := v.now()
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", v.Credentials.AccessKeyID+"/"+v.scope(t))
+	query.Set("X-Amz-Date", t.Format(sigV4TimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if v.Credentials.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", v.Credentials.SessionToken)
+	}
+	u.RawPath = awsEscape(u.Path, false)
+	canonical := strings.Join([]string{
+		method,
+		canonicalPath(u),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		UnsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", v.signature(t, canonical))
+	u.RawQuery = canonicalQuery(query)
+	return u.String(), nil
+}
+
+func (v *SigV4) signature(t time.Time, canonical string) string {
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := sigV4Algorithm + "\n" +
+		t.Format(sigV4TimeFormat) + "\n" +
+		v.scope(t) + "\n" +
+		hex.EncodeToString(hash[:])
+	key := hmacSHA256([]byte("AWS4"+v.Credentials.SecretAccessKey), t.Format(sigV4DateFormat))
+	key = hmacSHA256(key, v.Region)
+	key = hmacSHA256(key, v.Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func canonicalPath(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	return awsEscape(u.Path, false)
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, awsEscape(key, true)+"="+awsEscape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func canonicalHeaders(r *http.Request) (headers, signed string) {
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for key, vs := range r.Header {
+		key = strings.ToLower(key)
+		if key == "authorization" || key == "user-agent" {
+			continue
+		}
+		trimmed := make([]string, len(vs))
+		for i, v := range vs {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		values[key] = strings.Join(trimmed, ",")
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key + ":" + values[key] + "\n")
+	}
+	return b.String(), strings.Join(keys, ";")
+}
+
+func awsEscape(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&15])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,212 @@
+package httpr
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+)
+
+type TransportSnapshot struct {
+	Custom                bool   `json:"custom"`
+	MaxIdleConns          int    `json:"max_idle_conns"`
+	MaxIdleConnsPerHost   int    `json:"max_idle_conns_per_host"`
+	MaxConnsPerHost       int    `json:"max_conns_per_host"`
+	IdleConnTimeout       string `json:"idle_conn_timeout"`
+	TLSHandshakeTimeout   string `json:"tls_handshake_timeout"`
+	ResponseHeaderTimeout string `json:"response_header_timeout"`
+	ForceAttemptHTTP2     bool   `json:"force_attempt_http2"`
+	DisableKeepAlives     bool   `json:"disable_keep_alives"`
+	Proxy                 bool   `json:"proxy"`
+	CustomDialer          bool   `json:"custom_dialer"`
+	InsecureSkipVerify    bool   `json:"insecure_skip_verify"`
+	ServerName            string `json:"server_name,omitempty"`
+	CustomRootCAs         bool   `json:"custom_root_cas"`
+	ClientCertificates    int    `json:"client_certificates"`
+}
+
+type RetrySnapshot struct {
+	MaxAttempts        int      `json:"max_attempts"`
+	Delays             []string `json:"delays,omitempty"`
+	BaseDelay          string   `json:"base_delay"`
+	MaxDelay           string   `json:"max_delay"`
+	Jitter             float64  `json:"jitter"`
+	RetryOnStatus      []int    `json:"retry_on_status,omitempty"`
+	RetryOn            []string `json:"retry_on,omitempty"`
+	RespectRetryAfter  bool     `json:"respect_retry_after"`
+	AllowNonIdempotent bool     `json:"allow_non_idempotent"`
+}
+
+type Snapshot struct {
+	Name             string            `json:"name,omitempty"`
+	Host             string            `json:"host,omitempty"`
+	Hosts            []string          `json:"hosts,omitempty"`
+	Paths            map[string]string `json:"paths,omitempty"`
+	Header           http.Header       `json:"header,omitempty"`
+	Timeout          string            `json:"timeout"`
+	Debug            bool              `json:"debug"`
+	DebugCurl        bool              `json:"debug_curl"`
+	SpoolThreshold   int64             `json:"spool_threshold,omitempty"`
+	Retry            *RetrySnapshot    `json:"retry,omitempty"`
+	Before           []string          `json:"before,omitempty"`
+	BeforeRequest    []string          `json:"before_request,omitempty"`
+	AfterHooks       []string          `json:"after_hooks,omitempty"`
+	Middlewares      []string          `json:"middlewares,omitempty"`
+	ResponseStages   []string          `json:"response_stages,omitempty"`
+	Credentials      []string          `json:"credentials,omitempty"`
+	CostHooks        []string          `json:"cost_hooks,omitempty"`
+	Transforms       []string          `json:"transforms,omitempty"`
+	CookieJar        bool              `json:"cookie_jar"`
+	Crawler          bool              `json:"crawler"`
+	Audit            bool              `json:"audit"`
+	Metrics          string            `json:"metrics,omitempty"`
+	Resolver         string            `json:"resolver,omitempty"`
+	HostLimiter      bool              `json:"host_limiter"`
+	QuotaDelay       bool              `json:"quota_delay"`
+	QuotaThreshold   int               `json:"quota_threshold,omitempty"`
+	TrafficClass     string            `json:"traffic_class,omitempty"`
+	SniffCharset     bool              `json:"sniff_charset"`
+	Transport        TransportSnapshot `json:"transport"`
+	ConfigurationErr string            `json:"configuration_error,omitempty"`
+}
+
+func funcName(fn interface{}) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	if f := runtime.FuncForPC(v.Pointer()); f != nil {
+		return f.Name()
+	}
+	return "unknown"
+}
+
+func typeName(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func snapshotRetry(p *RetryPolicy) *RetrySnapshot {
+	if p == nil {
+		return nil
+	}
+	rs := &RetrySnapshot{
+		MaxAttempts:        p.attempts(),
+		BaseDelay:          p.BaseDelay.String(),
+		MaxDelay:           p.MaxDelay.String(),
+		Jitter:             p.Jitter,
+		RetryOnStatus:      append([]int(nil), p.RetryOnStatus...),
+		RespectRetryAfter:  p.RespectRetryAfter,
+		AllowNonIdempotent: p.AllowNonIdempotent,
+	}
+	for _, d := range p.Delays {
+		rs.Delays = append(rs.Delays, d.String())
+	}
+	for _, c := range p.RetryOn {
+		rs.RetryOn = append(rs.RetryOn, c.String())
+	}
+	return rs
+}
+
+func snapshotTransport(c *http.Client) (ts TransportSnapshot) {
+	t, ok := c.Transport.(*http.Transport)
+	if c.Transport != nil && !ok {
+		ts.Custom = true
+		return
+	}
+	if !ok {
+		t = defaultTransport
+	}
+	ts = TransportSnapshot{
+		Custom:                t != defaultTransport,
+		MaxIdleConns:          t.MaxIdleConns,
+		MaxIdleConnsPerHost:   t.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       t.MaxConnsPerHost,
+		IdleConnTimeout:       t.IdleConnTimeout.String(),
+		TLSHandshakeTimeout:   t.TLSHandshakeTimeout.String(),
+		ResponseHeaderTimeout: t.ResponseHeaderTimeout.String(),
+		ForceAttemptHTTP2:     t.ForceAttemptHTTP2,
+		DisableKeepAlives:     t.DisableKeepAlives,
+		Proxy:                 t.Proxy != nil,
+		CustomDialer:          t.DialContext != nil && t != defaultTransport,
+	}
+	if conf := t.TLSClientConfig; conf != nil {
+		ts.InsecureSkipVerify = conf.InsecureSkipVerify
+		ts.ServerName = conf.ServerName
+		ts.CustomRootCAs = conf.RootCAs != nil
+		ts.ClientCertificates = len(conf.Certificates)
+	}
+	return
+}
+
+func (s *Service) Config() Snapshot {
+	snap := Snapshot{
+		Name:           s.name,
+		Host:           s.host,
+		Hosts:          append([]string(nil), s.hosts...),
+		Timeout:        s.client.Timeout.String(),
+		Debug:          s.conf.Debug,
+		DebugCurl:      s.conf.DebugCurl,
+		SpoolThreshold: s.conf.SpoolThreshold,
+		Retry:          snapshotRetry(s.retryPolicy),
+		CookieJar:      s.client.Jar != nil,
+		Crawler:        s.robots != nil,
+		Audit:          s.auditor != nil,
+		Metrics:        typeName(s.metrics),
+		Resolver:       typeName(s.resolver),
+		HostLimiter:    s.limiter != nil,
+		QuotaDelay:     s.quotaDelay,
+		QuotaThreshold: s.quotaThreshold,
+		TrafficClass:   s.trafficClass,
+		SniffCharset:   s.sniffCharset,
+		Transport:      snapshotTransport(s.client),
+	}
+	if len(s.paths) > 0 {
+		snap.Paths = make(map[string]string, len(s.paths))
+		for k, v := range s.paths {
+			snap.Paths[k] = v
+		}
+	}
+	if s.header != nil {
+		r := defaultRedactor
+		if s.redact != nil {
+			r = s.redact
+		}
+		snap.Header = r.header(s.header)
+	}
+	for _, hook := range s.before {
+		snap.Before = append(snap.Before, funcName(hook))
+	}
+	for _, hook := range s.beforeRequest {
+		snap.BeforeRequest = append(snap.BeforeRequest, funcName(hook))
+	}
+	for _, hook := range s.afterHooks {
+		snap.AfterHooks = append(snap.AfterHooks, funcName(hook))
+	}
+	for _, hook := range s.after {
+		snap.AfterHooks = append(snap.AfterHooks, funcName(hook))
+	}
+	for _, m := range s.middlewares {
+		snap.Middlewares = append(snap.Middlewares, funcName(m))
+	}
+	for _, stage := range s.pipeline {
+		snap.ResponseStages = append(snap.ResponseStages, stage.Name)
+	}
+	for _, c := range s.credentials {
+		snap.Credentials = append(snap.Credentials, c.header)
+	}
+	for _, hook := range s.costHooks {
+		snap.CostHooks = append(snap.CostHooks, funcName(hook))
+	}
+	for key := range s.transforms {
+		snap.Transforms = append(snap.Transforms, key)
+	}
+	sort.Strings(snap.Transforms)
+	if s.err != nil {
+		snap.ConfigurationErr = s.err.Error()
+	}
+	return snap
+}
@@ -0,0 +1,80 @@
+package httpr
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func (rsp *Response) bodyReader() io.ReadCloser {
+	var r io.Reader = rsp.rsp.Body
+	if len(rsp.tees) > 0 {
+		r = io.TeeReader(r, io.MultiWriter(rsp.tees...))
+		rsp.tees = nil
+	}
+	return readCloser{r, rsp.rsp.Body}
+}
+
+func (rsp *Response) spool(threshold int64, dir string) (err error) {
+	body := rsp.bodyReader()
+	defer body.Close()
+	head, err := ioutil.ReadAll(io.LimitReader(body, threshold+1))
+	if err != nil {
+		return
+	}
+	if int64(len(head)) <= threshold {
+		rsp.raw = head
+		return
+	}
+	f, err := ioutil.TempFile(dir, "httpr-spool-")
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	rsp.spoolFile = f.Name()
+	if _, err = f.Write(head); err != nil {
+		return
+	}
+	_, err = io.Copy(f, body)
+	return
+}
+
+func (rsp *Response) buffered() bool {
+	return rsp.raw != nil || rsp.spoolFile != ""
+}
+
+func (rsp *Response) rawReader() (r io.ReadCloser, err error) {
+	switch {
+	case rsp.raw != nil:
+		r = ioutil.NopCloser(bytes.NewReader(rsp.raw))
+	case rsp.spoolFile != "":
+		r, err = os.Open(rsp.spoolFile)
+	default:
+		r = rsp.bodyReader()
+	}
+	return
+}
+
+func (rsp *Response) Reader() (r io.ReadCloser, err error) {
+	if rsp.body != nil || rsp.err != nil {
+		r, err = ioutil.NopCloser(bytes.NewReader(rsp.body)), rsp.err
+		return
+	}
+	return rsp.rawReader()
+}
+
+func (rsp *Response) Close() (err error) {
+	err = rsp.rsp.Body.Close()
+	if rsp.spoolFile != "" {
+		if rerr := os.Remove(rsp.spoolFile); rerr != nil && !os.IsNotExist(rerr) {
+			err = rerr
+		}
+	}
+	return
+}
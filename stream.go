@@ -0,0 +1,76 @@
+package httpr
+
+import (
+	"io"
+	"os"
+)
+
+type ProgressFunc func(read, total int64)
+
+type progressReader struct {
+	r        io.Reader
+	read     int64
+	total    int64
+	progress []ProgressFunc
+}
+
+func (p *progressReader) Read(b []byte) (n int, err error) {
+	n, err = p.r.Read(b)
+	p.read += int64(n)
+	for _, fn := range p.progress {
+		fn(p.read, p.total)
+	}
+	return
+}
+
+func (rsp *Response) withProgress(r io.Reader, progress []ProgressFunc) io.Reader {
+	if len(progress) == 0 {
+		return r
+	}
+	return &progressReader{
+		r:        r,
+		total:    rsp.rsp.ContentLength,
+		progress: progress,
+	}
+}
+
+func (rsp *Response) Stream(fn func(chunk []byte) error, progress ...ProgressFunc) (err error) {
+	body, err := rsp.Reader()
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	r := rsp.withProgress(body, progress)
+	buf := make([]byte, 32<<10)
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			if err = fn(buf[:n]); err != nil {
+				return
+			}
+		}
+		if rerr == io.EOF {
+			return
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+}
+
+func (rsp *Response) ToFile(path string, progress ...ProgressFunc) (err error) {
+	body, err := rsp.Reader()
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	f, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	_, err = io.Copy(f, rsp.withProgress(body, progress))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return
+}
@@ -0,0 +1,58 @@
+package httpr
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+func (s *Service) DialTimeout(d time.Duration) *Service {
+	s.dialTimeout = d
+	s.transport().DialContext = s.dialContext
+	return s
+}
+
+func (s *Service) TLSHandshakeTimeout(d time.Duration) *Service {
+	s.transport().TLSHandshakeTimeout = d
+	return s
+}
+
+func (s *Service) ResponseHeaderTimeout(d time.Duration) *Service {
+	s.transport().ResponseHeaderTimeout = d
+	return s
+}
+
+func (req *Request) Timeout(d time.Duration) *Request {
+	req.timeout = d
+	return req
+}
+
+func (req *Request) timeoutContext(ctx context.Context) context.Context {
+	if req.timeout <= 0 {
+		return ctx
+	}
+	ctx, req.cancel = context.WithTimeout(ctx, req.timeout)
+	return ctx
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func (req *Request) releaseTimeout(rsp *Response, err error) {
+	if req.cancel == nil {
+		return
+	}
+	if err != nil || rsp == nil {
+		req.cancel()
+		return
+	}
+	rsp.rsp.Body = &cancelBody{ReadCloser: rsp.rsp.Body, cancel: req.cancel}
+}
@@ -0,0 +1,79 @@
+package httpr
+
+import (
+	"context"
+	"net/http"
+)
+
+type Span interface {
+	SetAttributes(attrs map[string]interface{})
+	RecordError(err error)
+	End()
+}
+
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+	Inject(ctx context.Context, header http.Header)
+}
+
+func (s *Service) WithTracer(t Tracer) *Service {
+	s.tracer = t
+	return s
+}
+
+func (req *Request) tracer() Tracer {
+	if req.service != nil {
+		return req.service.tracer
+	}
+	return nil
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(map[string]interface{}) {}
+func (noopSpan) RecordError(error)                    {}
+func (noopSpan) End()                                 {}
+
+func (req *Request) startSpan(r *http.Request) (*http.Request, Span) {
+	t := req.tracer()
+	if t == nil {
+		return r, noopSpan{}
+	}
+	ctx, span := t.Start(r.Context(), "HTTP "+r.Method)
+	span.SetAttributes(map[string]interface{}{
+		"http.request.method": r.Method,
+		"url.full":            req.redactor().url(r.URL),
+		"server.address":      r.URL.Host,
+	})
+	if id, ok := req.tags[GroupIDTag]; ok {
+		span.SetAttributes(map[string]interface{}{
+			"httpr.group_id": id,
+		})
+	}
+	return r.WithContext(ctx), span
+}
+
+func (req *Request) startAttemptSpan(r *http.Request, attempt int) (*http.Request, Span) {
+	t := req.tracer()
+	if t == nil {
+		return r, noopSpan{}
+	}
+	ctx, span := t.Start(r.Context(), "HTTP "+r.Method+" attempt")
+	span.SetAttributes(map[string]interface{}{
+		"http.request.resend_count": attempt - 1,
+	})
+	t.Inject(ctx, r.Header)
+	return r.WithContext(ctx), span
+}
+
+func endSpan(span Span, rsp *Response, err error) {
+	if rsp != nil {
+		span.SetAttributes(map[string]interface{}{
+			"http.response.status_code": rsp.StatusCode(),
+		})
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
@@ -0,0 +1,64 @@
+package httpr
+
+import (
+	"bytes"
+	"regexp"
+)
+
+type BodyTransform func(body []byte) ([]byte, error)
+
+var (
+	xssiPrefixes = [][]byte{
+		[]byte(")]}'"),
+		[]byte(")]}"),
+		[]byte("while(1);"),
+		[]byte("for(;;);"),
+	}
+	jsonpPattern = regexp.MustCompile(`(?s)^\s*(?:/\*\*/\s*)?[A-Za-z_$][\w$.]*\s*\((.*)\)\s*;?\s*$`)
+)
+
+func StripXSSI() BodyTransform {
+	return func(body []byte) ([]byte, error) {
+		trimmed := bytes.TrimLeft(body, " \t\r\n")
+		for _, prefix := range xssiPrefixes {
+			if bytes.HasPrefix(trimmed, prefix) {
+				return trimmed[len(prefix):], nil
+			}
+		}
+		return body, nil
+	}
+}
+
+func UnwrapJSONP() BodyTransform {
+	return func(body []byte) ([]byte, error) {
+		if m := jsonpPattern.FindSubmatch(body); m != nil {
+			return m[1], nil
+		}
+		return body, nil
+	}
+}
+
+func (s *Service) Transform(uriKey string, transforms ...BodyTransform) *Service {
+	if s.transforms == nil {
+		s.transforms = map[string][]BodyTransform{}
+	}
+	s.transforms[uriKey] = append(s.transforms[uriKey], transforms...)
+	return s
+}
+
+func (rsp *Response) decodable() (bs []byte, err error) {
+	if rsp.fail != nil {
+		err = rsp.fail
+		return
+	}
+	bs, err = rsp.Bytes()
+	if err != nil || rsp.req.service == nil || rsp.req.endpoint == "" {
+		return
+	}
+	for _, t := range rsp.req.service.transforms[rsp.req.endpoint] {
+		if bs, err = t(bs); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,153 @@
+package httpr
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrCustomTransport = errors.New("httpr: setting requires an *http.Transport; configure the custom RoundTripper directly")
+
+	defaultTransport = http.DefaultTransport.(*http.Transport)
+	defaultClients   sync.Map
+)
+
+type clientKey struct {
+	timeout time.Duration
+	sni     string
+}
+
+func defaultClient(timeout time.Duration, sni string) *http.Client {
+	key := clientKey{timeout: timeout, sni: sni}
+	if c, ok := defaultClients.Load(key); ok {
+		return c.(*http.Client)
+	}
+	c := &http.Client{Timeout: timeout}
+	if sni != "" {
+		c, _ = withServerName(c, sni)
+	}
+	v, _ := defaultClients.LoadOrStore(key, c)
+	return v.(*http.Client)
+}
+
+func (s *Service) sniClient(name string) (*http.Client, error) {
+	if c, ok := s.sniClients.Load(name); ok {
+		return c.(*http.Client), nil
+	}
+	c, err := withServerName(s.client, name)
+	if err != nil {
+		return nil, err
+	}
+	v, _ := s.sniClients.LoadOrStore(name, c)
+	return v.(*http.Client), nil
+}
+
+func (s *Service) resetSNIClients() {
+	s.sniClients.Range(func(key, _ interface{}) bool {
+		s.sniClients.Delete(key)
+		return true
+	})
+}
+
+func baseTransport(c *http.Client) (t *http.Transport, err error) {
+	switch rt := c.Transport.(type) {
+	case nil:
+		t = defaultTransport
+	case *http.Transport:
+		t = rt
+	default:
+		err = ErrCustomTransport
+	}
+	return
+}
+
+func withServerName(c *http.Client, name string) (*http.Client, error) {
+	t, err := baseTransport(c)
+	if err != nil {
+		return nil, err
+	}
+	t = t.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	t.TLSClientConfig.ServerName = name
+	cc := *c
+	cc.Transport = t
+	return &cc, nil
+}
+
+func (s *Service) transport() *http.Transport {
+	t, err := baseTransport(s.client)
+	if err != nil {
+		if s.err == nil {
+			s.err = err
+		}
+		return &http.Transport{}
+	}
+	if t == defaultTransport {
+		t = t.Clone()
+		s.client.Transport = t
+	}
+	s.resetSNIClients()
+	return t
+}
+
+func (s *Service) tlsConfig() *tls.Config {
+	t := s.transport()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	return t.TLSClientConfig
+}
+
+func (s *Service) TLS(config *tls.Config) *Service {
+	s.transport().TLSClientConfig = config
+	return s
+}
+
+func (s *Service) RootCAFile(path string) *Service {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		s.err = err
+		return s
+	}
+	conf := s.tlsConfig()
+	if conf.RootCAs == nil {
+		conf.RootCAs = x509.NewCertPool()
+	}
+	if !conf.RootCAs.AppendCertsFromPEM(pem) {
+		s.err = errors.New("httpr: no certificates found in " + path)
+	}
+	return s
+}
+
+func (s *Service) ClientCertFiles(certFile, keyFile string) *Service {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		s.err = err
+		return s
+	}
+	conf := s.tlsConfig()
+	conf.Certificates = append(conf.Certificates, cert)
+	return s
+}
+
+func (s *Service) InsecureSkipVerify() *Service {
+	s.tlsConfig().InsecureSkipVerify = true
+	return s
+}
+
+func (s *Service) WithTransport(rt http.RoundTripper) *Service {
+	s.client.Transport = rt
+	s.resetSNIClients()
+	return s
+}
+
+func (s *Service) HTTPClient() *http.Client {
+	return s.client
+}
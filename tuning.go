@@ -0,0 +1,59 @@
+package httpr
+
+import "time"
+
+func (s *Service) tune(c Conf) {
+	if c.MaxIdleConns == 0 && c.MaxIdleConnsPerHost == 0 && c.MaxConnsPerHost == 0 &&
+		c.IdleConnTimeout == 0 && !c.ForceAttemptHTTP2 && !c.DisableKeepAlives {
+		return
+	}
+	t := s.transport()
+	if c.MaxIdleConns > 0 {
+		t.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+	if c.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = c.MaxConnsPerHost
+	}
+	if c.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = c.IdleConnTimeout
+	}
+	if c.ForceAttemptHTTP2 {
+		t.ForceAttemptHTTP2 = true
+	}
+	if c.DisableKeepAlives {
+		t.DisableKeepAlives = true
+	}
+}
+
+func (s *Service) MaxIdleConns(n int) *Service {
+	s.transport().MaxIdleConns = n
+	return s
+}
+
+func (s *Service) MaxIdleConnsPerHost(n int) *Service {
+	s.transport().MaxIdleConnsPerHost = n
+	return s
+}
+
+func (s *Service) MaxConnsPerHost(n int) *Service {
+	s.transport().MaxConnsPerHost = n
+	return s
+}
+
+func (s *Service) IdleConnTimeout(d time.Duration) *Service {
+	s.transport().IdleConnTimeout = d
+	return s
+}
+
+func (s *Service) ForceHTTP2(force bool) *Service {
+	s.transport().ForceAttemptHTTP2 = force
+	return s
+}
+
+func (s *Service) DisableKeepAlives(disable bool) *Service {
+	s.transport().DisableKeepAlives = disable
+	return s
+}
@@ -0,0 +1,117 @@
+package httpr
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var ErrNoTunnelProxy = errors.New("httpr: no proxy configured for tunnel target")
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+func (s *Service) proxyFor(target string) (proxy *url.URL, err error) {
+	t, err := baseTransport(s.client)
+	if err != nil || t.Proxy == nil {
+		return
+	}
+	r, err := http.NewRequest(http.MethodConnect, "https://"+target, nil)
+	if err != nil {
+		return
+	}
+	return t.Proxy(r)
+}
+
+func (s *Service) Tunnel(ctx context.Context, target string) (conn net.Conn, err error) {
+	proxy, err := s.proxyFor(target)
+	if err != nil {
+		return
+	}
+	if proxy == nil {
+		err = ErrNoTunnelProxy
+		return
+	}
+	t, err := baseTransport(s.client)
+	if err != nil {
+		return
+	}
+	addr := proxy.Host
+	if proxy.Port() == "" {
+		port := "80"
+		if proxy.Scheme == "https" {
+			port = "443"
+		}
+		addr = net.JoinHostPort(proxy.Hostname(), port)
+	}
+	dial := t.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	if conn, err = dial(ctx, "tcp", addr); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			conn.Close()
+			conn = nil
+		}
+	}()
+	if proxy.Scheme == "https" {
+		conf := &tls.Config{}
+		if t.TLSClientConfig != nil {
+			conf = t.TLSClientConfig.Clone()
+		}
+		conf.ServerName = proxy.Hostname()
+		tc := tls.Client(conn, conf)
+		if err = tc.HandshakeContext(ctx); err != nil {
+			return
+		}
+		conn = tc
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	r := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: target},
+		Host:   target,
+		Header: http.Header{},
+	}
+	for key, values := range t.ProxyConnectHeader {
+		r.Header[key] = append([]string(nil), values...)
+	}
+	if u := proxy.User; u != nil {
+		password, _ := u.Password()
+		r.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(u.Username()+":"+password)))
+	}
+	if err = r.Write(conn); err != nil {
+		return
+	}
+	br := bufio.NewReader(conn)
+	rsp, err := http.ReadResponse(br, r)
+	if err != nil {
+		return
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("httpr: proxy CONNECT %s: %s", target, rsp.Status)
+		return
+	}
+	conn = &bufferedConn{Conn: conn, r: br}
+	return
+}
@@ -0,0 +1,230 @@
+package httpr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const TusResumable = "1.0.0"
+
+var (
+	ErrUploadLocation = errors.New("httpr: tus creation response has no Location")
+	ErrUploadStalled  = errors.New("httpr: tus server did not advance Upload-Offset")
+)
+
+type UploadOption func(*uploadConfig)
+
+type uploadConfig struct {
+	location  string
+	chunkSize int64
+	metadata  map[string]string
+	retries   int
+	delay     time.Duration
+	progress  []ProgressFunc
+	ctx       context.Context
+	err       error
+}
+
+func UploadURL(location string) UploadOption {
+	return func(c *uploadConfig) {
+		c.location = location
+	}
+}
+
+func UploadChunkSize(n int64) UploadOption {
+	return func(c *uploadConfig) {
+		if n < 1 {
+			c.err = fmt.Errorf("httpr: invalid upload chunk size %d", n)
+			return
+		}
+		c.chunkSize = n
+	}
+}
+
+func UploadMetadata(key, value string) UploadOption {
+	return func(c *uploadConfig) {
+		if c.metadata == nil {
+			c.metadata = map[string]string{}
+		}
+		c.metadata[key] = value
+	}
+}
+
+func UploadRetries(n int, delay time.Duration) UploadOption {
+	return func(c *uploadConfig) {
+		c.retries, c.delay = n, delay
+	}
+}
+
+func UploadProgress(fn ProgressFunc) UploadOption {
+	return func(c *uploadConfig) {
+		c.progress = append(c.progress, fn)
+	}
+}
+
+type UploadError struct {
+	Location string
+	Offset   int64
+	Err      error
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("httpr: upload %s stopped at offset %d: %v", e.Location, e.Offset, e.Err)
+}
+
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+func (c *uploadConfig) encodeMetadata() string {
+	keys := make([]string, 0, len(c.metadata))
+	for key := range c.metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + " " + base64.StdEncoding.EncodeToString([]byte(c.metadata[key]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (req *Request) UploadResumable(src io.ReaderAt, size int64, opts ...UploadOption) (location string, err error) {
+	return req.UploadResumableContext(req.context(), src, size, opts...)
+}
+
+func (req *Request) UploadResumableContext(ctx context.Context, src io.ReaderAt, size int64, opts ...UploadOption) (location string, err error) {
+	conf := uploadConfig{chunkSize: 4 << 20, retries: 3, delay: time.Second, ctx: ctx}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	if conf.err != nil {
+		return "", conf.err
+	}
+	location = conf.location
+	if location == "" {
+		if location, err = req.tusCreate(size, &conf); err != nil {
+			return
+		}
+	}
+	var offset int64
+	failures := 0
+	resync := conf.location != ""
+	for {
+		if resync {
+			offset, err = req.tusOffset(location, &conf)
+			resync = false
+		}
+		if err == nil {
+			if offset >= size {
+				return
+			}
+			offset, err = req.tusPatch(location, src, offset, size, &conf)
+		}
+		if err == nil {
+			failures = 0
+			continue
+		}
+		if failures++; failures > conf.retries {
+			err = &UploadError{Location: location, Offset: offset, Err: err}
+			return
+		}
+		if err = sleep(conf.ctx, conf.delay); err != nil {
+			err = &UploadError{Location: location, Offset: offset, Err: err}
+			return
+		}
+		resync = true
+	}
+}
+
+func (req *Request) tusCreate(size int64, conf *uploadConfig) (location string, err error) {
+	if req.method == "" || req.method == http.MethodGet {
+		req.method = http.MethodPost
+	}
+	req.Header("Tus-Resumable", TusResumable).
+		Header("Upload-Length", strconv.FormatInt(size, 10))
+	if len(conf.metadata) > 0 {
+		req.Header("Upload-Metadata", conf.encodeMetadata())
+	}
+	rsp, err := req.Do(conf.ctx)
+	if err != nil {
+		return
+	}
+	defer discard(rsp)
+	if rsp.StatusCode() != http.StatusCreated {
+		err = rsp.statusError()
+		return
+	}
+	if location = rsp.Header().Get("Location"); location == "" {
+		err = ErrUploadLocation
+		return
+	}
+	location = resolveLocation(rsp, location)
+	return
+}
+
+func (req *Request) tusOffset(location string, conf *uploadConfig) (offset int64, err error) {
+	rsp, err := req.derive(http.MethodHead, location).
+		Header("Tus-Resumable", TusResumable).
+		Do(conf.ctx)
+	if err != nil {
+		return
+	}
+	defer discard(rsp)
+	if rsp.StatusCode() != http.StatusOK && rsp.StatusCode() != http.StatusNoContent {
+		err = rsp.statusError()
+		return
+	}
+	return uploadOffset(rsp)
+}
+
+func (req *Request) tusPatch(location string, src io.ReaderAt, offset, size int64, conf *uploadConfig) (next int64, err error) {
+	n := conf.chunkSize
+	if offset+n > size {
+		n = size - offset
+	}
+	chunk := make([]byte, n)
+	if _, err = src.ReadAt(chunk, offset); err != nil && err != io.EOF {
+		return offset, err
+	}
+	rsp, err := req.derive(http.MethodPatch, location).
+		Header("Tus-Resumable", TusResumable).
+		Header("Upload-Offset", strconv.FormatInt(offset, 10)).
+		Header("Content-Type", "application/offset+octet-stream").
+		Body(bytes.NewReader(chunk)).
+		Do(conf.ctx)
+	if err != nil {
+		return offset, err
+	}
+	defer discard(rsp)
+	if rsp.StatusCode() != http.StatusNoContent && rsp.StatusCode() != http.StatusOK {
+		return offset, rsp.statusError()
+	}
+	if next, err = uploadOffset(rsp); err != nil {
+		return offset, err
+	}
+	if next <= offset {
+		return offset, ErrUploadStalled
+	}
+	for _, fn := range conf.progress {
+		fn(next, size)
+	}
+	return
+}
+
+func uploadOffset(rsp *Response) (offset int64, err error) {
+	offset, err = strconv.ParseInt(rsp.Header().Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		err = fmt.Errorf("httpr: invalid Upload-Offset: %w", err)
+	}
+	return
+}
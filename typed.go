@@ -0,0 +1,33 @@
+package httpr
+
+func JSON[T any](req *Request) (v T, err error) {
+	rsp, err := req.Response()
+	if err != nil {
+		return
+	}
+	err = rsp.ToJson(&v)
+	return
+}
+
+func XML[T any](req *Request) (v T, err error) {
+	rsp, err := req.Response()
+	if err != nil {
+		return
+	}
+	err = rsp.ToXML(&v)
+	return
+}
+
+func Into[T any](req *Request) (v T, err error) {
+	rsp, err := req.Response()
+	if err != nil {
+		return
+	}
+	v, err = As[T](rsp)
+	return
+}
+
+func As[T any](rsp *Response) (v T, err error) {
+	err = rsp.To(&v)
+	return
+}
@@ -0,0 +1,49 @@
+package httpr
+
+import "net/http"
+
+const Version = "0.9.0"
+
+var DefaultUserAgent = "httpr/" + Version + " (+https://github.com/heramerom/httpr)"
+
+func (s *Service) UserAgent(ua string) *Service {
+	return s.RawHeader("User-Agent", ua)
+}
+
+func (req *Request) UserAgent(ua string) *Request {
+	return req.RawHeader("User-Agent", ua)
+}
+
+func (req *Request) override(key string) {
+	key = http.CanonicalHeaderKey(key)
+	if req.inherited[key] {
+		delete(req.inherited, key)
+		req.header.Del(key)
+	}
+}
+
+func (req *Request) DelHeader(key string) *Request {
+	key = http.CanonicalHeaderKey(key)
+	req.header.Del(key)
+	if req.dropped == nil {
+		req.dropped = map[string]bool{}
+	}
+	req.dropped[key] = true
+	return req
+}
+
+func (req *Request) layerHeaders(r *http.Request) {
+	for key := range req.dropped {
+		if _, set := req.header[key]; set {
+			continue
+		}
+		r.Header.Del(key)
+	}
+	if _, ok := r.Header["User-Agent"]; !ok {
+		if req.dropped["User-Agent"] {
+			r.Header["User-Agent"] = []string{""}
+		} else {
+			r.Header.Set("User-Agent", DefaultUserAgent)
+		}
+	}
+}